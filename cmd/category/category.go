@@ -4,13 +4,8 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package category
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
-
 	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
-	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
-	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/migrator"
 	"github.com/spf13/cobra"
 )
 
@@ -18,45 +13,14 @@ import (
 var categoryCmd = &cobra.Command{
 	Use:   "category",
 	Short: "Tranfer Mario Categories from mysql to dynamodb",
-	RunE: func(cmd *cobra.Command, args []string) error {
-
-		bDryRun, err := cmd.Flags().GetBool("dry-run")
-		if err != nil {
-			return fmt.Errorf("error parsing argument dry-run: %s", err)
-		}
-
-		cfg, err := reldb.Configuration()
-		if err != nil {
-			return fmt.Errorf("error fetching configuration: %s", err)
-		}
-
-		relDBH, err := reldb.NewModel(cfg)
-		if err != nil {
-			return fmt.Errorf("error connecting to database: %s", err)
-		}
-
-		categories, err := relDBH.CategoryTree()
-		if err != nil {
-			return fmt.Errorf("error fetching categories in cmd: %s", err)
-		}
-
-		if !bDryRun {
-			batchSize := 200
-			count, err := model.AddCategoryBatch(context.Background(), categories, batchSize)
-			if err != nil {
-				return fmt.Errorf("error adding bulk categories: %s", err)
-			}
-			fmt.Println("Inserted ", count, " categories")
-			return nil
-		}
+	RunE: func(c *cobra.Command, args []string) error {
 
-		jsonBytes, err := json.MarshalIndent(&categories, "", "\t")
+		m, err := migrator.Lookup("category")
 		if err != nil {
-			return fmt.Errorf("error marshaling categories: %s", err)
+			return err
 		}
-		fmt.Println("Categories: ", string(jsonBytes))
 
-		return nil
+		return cmd.RunMigrations(c, []migrator.Migrator{m})
 	},
 }
 
@@ -71,5 +35,5 @@ func init() {
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
-	categoryCmd.Flags().BoolP("dry-run", "d", false, "Dump categories, dont insert")
+	cmd.AddMigrationFlags(categoryCmd)
 }
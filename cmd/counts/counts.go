@@ -0,0 +1,147 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package counts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+	"github.com/spf13/cobra"
+)
+
+// countsCmd represents the counts command
+var countsCmd = &cobra.Command{
+	Use:   "counts <changes-file>",
+	Short: "Adjust the stored product counts of categories for changed products",
+	Long: `Reads products that were activated, deactivated or moved, one JSON object
+per line (- for standard input), e.g.
+
+  {"iProdID": 12, "from": {"iPCatID": 3, "cStatus": "A"}, "to": {"iPCatID": 5, "cStatus": "A"}}
+
+and adds the net change to the product count of each category item with an
+atomic DynamoDB ADD. With --rollup the subtree counts of the categories and
+their stored ancestors are adjusted too. Feed every change exactly once:
+running the same file twice counts it twice.
+
+Categories without an item are listed and skipped. The deltas that were
+not applied, because their category is missing or the run stopped early,
+are recorded in the file given by --unapplied; run counts --deltas on that
+file to apply them without counting anything twice.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+
+		strNaming, err := c.Flags().GetString("naming")
+		if err != nil {
+			return fmt.Errorf("error parsing argument naming: %s", err)
+		}
+		if model.ItemNaming, err = model.ParseNaming(strNaming); err != nil {
+			return err
+		}
+
+		bRollup, err := c.Flags().GetBool("rollup")
+		if err != nil {
+			return fmt.Errorf("error parsing argument rollup: %s", err)
+		}
+
+		bDryRun, err := c.Flags().GetBool("dry-run")
+		if err != nil {
+			return fmt.Errorf("error parsing argument dry-run: %s", err)
+		}
+
+		bDeltas, err := c.Flags().GetBool("deltas")
+		if err != nil {
+			return fmt.Errorf("error parsing argument deltas: %s", err)
+		}
+
+		unappliedFile, err := c.Flags().GetString("unapplied")
+		if err != nil {
+			return fmt.Errorf("error parsing argument unapplied: %s", err)
+		}
+
+		table, err := model.NewTableBasics()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel, err := cmd.CommandContext(c)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
+		deltas, err := readDeltas(ctx, table, args[0], bDeltas, bRollup)
+		if err != nil {
+			return err
+		}
+		printDeltas(deltas)
+		if bDryRun || len(deltas) == 0 {
+			return nil
+		}
+
+		result, err := table.AddProductCounts(ctx, deltas)
+		fmt.Printf("Adjusted the counts of %d of %d categories\n", result.Updated, len(deltas))
+		if len(result.Missing) > 0 {
+			fmt.Printf("Categories without an item, not adjusted: %v\n", result.Missing)
+		}
+		if len(result.Unapplied) > 0 {
+			if err := reldb.WriteCountDeltas(unappliedFile, result.Unapplied); err != nil {
+				return err
+			}
+			fmt.Printf("%d deltas not applied recorded in %s\n", len(result.Unapplied), unappliedFile)
+		}
+
+		return err
+	},
+}
+
+// readDeltas reads the deltas to apply from fileName, which holds either
+// deltas or the product changes to net into deltas.
+func readDeltas(ctx context.Context, table *model.TableBasics, fileName string, bDeltas, bRollup bool) ([]reldb.CountDelta, error) {
+
+	if bDeltas {
+		return reldb.ReadCountDeltas(fileName)
+	}
+
+	changes, err := reldb.ReadProductChanges(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ancestors are those of the stored tree, which is what the
+	// subtree counts being adjusted were computed from
+	var categories []reldb.CategorySummary
+	if bRollup {
+		if categories, err = table.StoredCategories(ctx); err != nil {
+			return nil, fmt.Errorf("error fetching stored categories: %s", err)
+		}
+	}
+
+	return reldb.CountDeltas(changes, categories, bRollup), nil
+}
+
+func printDeltas(deltas []reldb.CountDelta) {
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "CATEGORY\tPRODUCTS\tSUBTREE\t")
+	for _, delta := range deltas {
+		fmt.Fprintf(tw, "%d\t%+d\t%+d\t\n", delta.IPCatID, delta.IProductCount, delta.ISubtreeCount)
+	}
+	tw.Flush()
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(countsCmd)
+
+	countsCmd.Flags().Bool("rollup", false, "Also adjust the subtree counts of the categories and their ancestors")
+	countsCmd.Flags().String("naming", string(model.NamingCamel), "Attribute names the categories were stored with: camel, source, short or field")
+	countsCmd.Flags().BoolP("dry-run", "d", false, "Print the adjustments, dont apply them")
+	countsCmd.Flags().Bool("deltas", false, "The file holds deltas recorded by --unapplied instead of product changes")
+	countsCmd.Flags().String("unapplied", "counts-unapplied.jsonl", "Where to record the deltas that were not applied")
+	cmd.AddTimeoutFlag(countsCmd)
+}
@@ -0,0 +1,253 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/images"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+	"github.com/spf13/cobra"
+)
+
+// checkCmd represents the images check command
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Find referenced images that are missing or empty on disk",
+	Long: `Resolves every path of the image manifest, built from MySQL or read
+with --manifest, against the local document root and reports missing and
+zero byte files, and image files on disk that nothing references.
+
+With --fix null or --fix replace the broken small, large and menu images
+are removed from, or replaced with --replacement in, the migrated product
+and category items. Additional and description images are only reported.`,
+	RunE: func(c *cobra.Command, args []string) error {
+
+		cfg, err := reldb.Configuration()
+		if err != nil {
+			return fmt.Errorf("error fetching configuration: %s", err)
+		}
+
+		resolver := images.Resolver{}
+		if resolver.Root, err = c.Flags().GetString("root"); err != nil {
+			return fmt.Errorf("error parsing argument root: %s", err)
+		}
+		if resolver.Root == "" {
+			resolver.Root = cfg.DynDocRoot
+		}
+		if resolver.Root == "" {
+			return fmt.Errorf("no document root, set dynDocRoot in the configuration or use --root")
+		}
+		if resolver.Prefixes, err = c.Flags().GetStringSlice("strip"); err != nil {
+			return fmt.Errorf("error parsing argument strip: %s", err)
+		}
+		if cfg.Remote.ImageRoot != "" {
+			resolver.Prefixes = append(resolver.Prefixes, cfg.Remote.ImageRoot)
+		}
+
+		manifestFile, err := c.Flags().GetString("manifest")
+		if err != nil {
+			return fmt.Errorf("error parsing argument manifest: %s", err)
+		}
+		bUnreferenced, err := c.Flags().GetBool("unreferenced")
+		if err != nil {
+			return fmt.Errorf("error parsing argument unreferenced: %s", err)
+		}
+		bJSON, err := c.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("error parsing argument json: %s", err)
+		}
+
+		fix, err := c.Flags().GetString("fix")
+		if err != nil {
+			return fmt.Errorf("error parsing argument fix: %s", err)
+		}
+		var replacement *string
+		switch fix {
+		case "", "null":
+		case "replace":
+			path, err := c.Flags().GetString("replacement")
+			if err != nil {
+				return fmt.Errorf("error parsing argument replacement: %s", err)
+			}
+			if path == "" {
+				return fmt.Errorf("--fix replace needs a --replacement image")
+			}
+			replacement = &path
+		default:
+			return fmt.Errorf("unknown fix %q (want null or replace)", fix)
+		}
+
+		ctx, cancel, err := cmd.CommandContext(c)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
+		var relDBH *reldb.Model
+		if manifestFile == "" || fix != "" {
+			if relDBH, err = reldb.NewModel(cfg); err != nil {
+				return fmt.Errorf("error connecting to database: %s", err)
+			}
+		}
+
+		var refs []images.Ref
+		if manifestFile != "" {
+			refs, err = images.ReadManifest(manifestFile)
+		} else {
+			refs, err = images.Manifest(ctx, relDBH)
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err := images.Check(refs, resolver, bUnreferenced)
+		if err != nil {
+			return err
+		}
+
+		if bJSON {
+			jsonBytes, err := json.MarshalIndent(result, "", "\t")
+			if err != nil {
+				return fmt.Errorf("error marshaling check result: %s", err)
+			}
+			fmt.Println(string(jsonBytes))
+		} else {
+			printCheck(result)
+		}
+
+		if fix == "" || len(result.Broken) == 0 {
+			return nil
+		}
+
+		return fixBroken(ctx, c, relDBH, result.Broken, replacement)
+	},
+}
+
+func printCheck(result images.CheckResult) {
+
+	missing, empty := 0, 0
+	if len(result.Broken) > 0 {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "PROBLEM\tENTITY\tID\tROLE\tPATH\tFILE")
+		for _, broken := range result.Broken {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", broken.Problem, broken.Entity, broken.ID, broken.Role, broken.Path, broken.File)
+			if broken.Problem == images.ProblemMissing {
+				missing++
+			} else {
+				empty++
+			}
+		}
+		tw.Flush()
+	}
+
+	if len(result.Unreferenced) > 0 {
+		fmt.Println("Unreferenced files:")
+		for _, file := range result.Unreferenced {
+			fmt.Printf("  %s\n", file)
+		}
+	}
+
+	fmt.Printf("%d references checked, %d missing, %d empty, %d unreferenced files\n",
+		result.Checked, missing, empty, len(result.Unreferenced))
+}
+
+// fixBroken removes or replaces the broken references the items hold,
+// once per item and field.
+func fixBroken(ctx context.Context, c *cobra.Command, relDBH *reldb.Model, broken []images.Broken, replacement *string) error {
+
+	strNaming, err := c.Flags().GetString("naming")
+	if err != nil {
+		return fmt.Errorf("error parsing argument naming: %s", err)
+	}
+	if model.ItemNaming, err = model.ParseNaming(strNaming); err != nil {
+		return err
+	}
+	bDryRun, err := c.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("error parsing argument dry-run: %s", err)
+	}
+
+	products, err := relDBH.Products(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint32]reldb.Product, len(products))
+	for _, product := range products {
+		byID[product.IProdID] = product
+	}
+
+	table, err := model.NewTableBasics()
+	if err != nil {
+		return err
+	}
+
+	action := "Removing"
+	if replacement != nil {
+		action = "Replacing"
+	}
+	done := map[string]bool{}
+	fixed := 0
+	for _, b := range broken {
+
+		field := b.ItemField()
+		if field == "" {
+			continue
+		}
+
+		var pk, sk string
+		switch b.Entity {
+		case "category":
+			pk, sk = model.CategoryKey(reldb.CategorySummary{IPCatID: b.ID})
+		case "product":
+			product, exists := byID[b.ID]
+			if !exists {
+				fmt.Printf("Skipping product %d, it is no longer in the database\n", b.ID)
+				continue
+			}
+			pk, sk = model.ProductKey(product)
+		default:
+			continue
+		}
+
+		key := pk + "/" + sk + "/" + field
+		if done[key] {
+			continue
+		}
+		done[key] = true
+
+		fmt.Printf("%s %s of %s/%s (%s)\n", action, field, pk, sk, b.Path)
+		if bDryRun {
+			continue
+		}
+		if err := table.SetImage(ctx, pk, sk, field, replacement); err != nil {
+			return err
+		}
+		fixed++
+	}
+	fmt.Printf("Fixed %d image references\n", fixed)
+
+	return nil
+}
+
+func init() {
+	imagesCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().String("root", "", "Local document root, dynDocRoot of the configuration by default")
+	checkCmd.Flags().StringSlice("strip", nil, "Path prefixes to cut off before resolving, besides the remote image root")
+	checkCmd.Flags().String("manifest", "", "Check this manifest file instead of building one from MySQL")
+	checkCmd.Flags().Bool("unreferenced", true, "Also list image files on disk that nothing references")
+	checkCmd.Flags().Bool("json", false, "Print the result as json")
+	checkCmd.Flags().String("fix", "", "Fix broken references in the migrated items: null or replace")
+	checkCmd.Flags().String("replacement", "", "Image to put in place of broken references with --fix replace")
+	checkCmd.Flags().String("naming", string(model.NamingCamel), "Attribute names the items were stored with: camel, source, short or field")
+	checkCmd.Flags().BoolP("dry-run", "d", false, "List the fixes, dont apply them")
+	cmd.AddTimeoutFlag(checkCmd)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/images"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+	"github.com/spf13/cobra"
+)
+
+// imagesCmd represents the images command
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Work with the images referenced by categories and products",
+}
+
+// manifestCmd represents the images manifest command
+var manifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "List every referenced image with its owner, role and alt text",
+	Long: `Collects the category menu images, the small, large and additional
+product images and the <img> tags of category and product descriptions into
+a csv or json manifest. Paths referenced more than once are flagged as
+duplicates, references without alt text as missing it.`,
+	RunE: func(c *cobra.Command, args []string) error {
+
+		strFormat, err := c.Flags().GetString("format")
+		if err != nil {
+			return fmt.Errorf("error parsing argument format: %s", err)
+		}
+		format, err := images.ParseFormat(strFormat)
+		if err != nil {
+			return err
+		}
+
+		outFile, err := c.Flags().GetString("output")
+		if err != nil {
+			return fmt.Errorf("error parsing argument output: %s", err)
+		}
+
+		cfg, err := reldb.Configuration()
+		if err != nil {
+			return fmt.Errorf("error fetching configuration: %s", err)
+		}
+
+		relDBH, err := reldb.NewModel(cfg)
+		if err != nil {
+			return fmt.Errorf("error connecting to database: %s", err)
+		}
+
+		ctx, cancel, err := cmd.CommandContext(c)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
+		refs, err := images.Manifest(ctx, relDBH)
+		if err != nil {
+			return fmt.Errorf("error collecting images: %s", err)
+		}
+
+		var w io.Writer = os.Stdout
+		if outFile != "-" {
+			file, err := os.Create(outFile)
+			if err != nil {
+				return fmt.Errorf("error creating %s: %s", outFile, err)
+			}
+			defer file.Close()
+			w = file
+		}
+		if err := images.Write(w, refs, format); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, images.Summary(refs))
+
+		return nil
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(imagesCmd)
+	imagesCmd.AddCommand(manifestCmd)
+
+	manifestCmd.Flags().String("format", string(images.FormatCSV), "Output format: csv or json")
+	manifestCmd.Flags().StringP("output", "o", "-", "File to write the manifest to, - for standard output")
+	cmd.AddTimeoutFlag(manifestCmd)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/lint"
+	"github.com/spf13/cobra"
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check the mysql catalog for problems before migrating",
+	Long: `Runs catalog checks against mysql and prints the flagged rows grouped by
+severity and check:
+
+  error    the row is lost or corrupted by the migration (orphaned or
+           cyclic categories, products in missing categories, empty names)
+  warning  the row migrates but renders badly (inactive categories,
+           duplicate url names, inconsistent prices)
+  info     worth a look (attributes without values)
+
+The command fails if any error was found.`,
+	RunE: func(c *cobra.Command, args []string) error {
+
+		strSeverity, err := c.Flags().GetString("min-severity")
+		if err != nil {
+			return fmt.Errorf("error parsing argument min-severity: %s", err)
+		}
+		minSeverity, err := lint.ParseSeverity(strSeverity)
+		if err != nil {
+			return err
+		}
+
+		bJSON, err := c.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("error parsing argument json: %s", err)
+		}
+
+		ctx, cancel, err := cmd.CommandContext(c)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
+		relDBH, err := cmd.SourceModel(ctx, c)
+		if err != nil {
+			return err
+		}
+		defer relDBH.Release()
+
+		report, err := lint.Run(ctx, relDBH, minSeverity)
+		if err != nil {
+			return err
+		}
+
+		if bJSON {
+			jsonBytes, err := json.MarshalIndent(report, "", "\t")
+			if err != nil {
+				return fmt.Errorf("error marshaling lint report: %s", err)
+			}
+			fmt.Println(string(jsonBytes))
+		} else {
+			report.Print(os.Stdout)
+		}
+
+		if count := report.Count(lint.SeverityError); count > 0 {
+			return fmt.Errorf("lint found %d errors", count)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().String("min-severity", string(lint.SeverityInfo), "Only run checks of this severity or worse: error, warning or info")
+	lintCmd.Flags().Bool("json", false, "Print the report as JSON")
+	lintCmd.Flags().Bool("snapshot", false, "Run all checks on one consistent mysql snapshot")
+	cmd.AddTimeoutFlag(lintCmd)
+}
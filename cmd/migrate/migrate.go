@@ -0,0 +1,45 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/migrator"
+	"github.com/spf13/cobra"
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Run registered entity migrations",
+}
+
+// allCmd represents the migrate all command
+var allCmd = &cobra.Command{
+	Use:   "all [entity...]",
+	Short: "Transfer all entities from mysql to dynamodb in dependency order",
+	Long: fmt.Sprintf(`Runs every registered migration, or only the named entities and the
+entities they depend on, so that dependencies are written first.
+
+Registered entities: %s`, strings.Join(migrator.Names(), ", ")),
+	RunE: func(c *cobra.Command, args []string) error {
+
+		migrators, err := migrator.Ordered(args...)
+		if err != nil {
+			return err
+		}
+
+		return cmd.RunMigrations(c, migrators)
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(allCmd)
+
+	cmd.AddMigrationFlags(allCmd)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package migratequery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// migrateQueryCmd represents the migrate-query command
+var migrateQueryCmd = &cobra.Command{
+	Use:   "migrate-query <file.sql>",
+	Short: "Transfer the rows of an arbitrary query from mysql to dynamodb",
+	Long: `Runs the query in <file.sql> and writes every row as an item.
+
+The columns named PK and SK (or the columns given by --pk and --sk) become
+the keys of the item, every other column is stored as an attribute with its
+type inferred from the column type. If <file.sql> does not exist as given it
+is looked up in the --sql-dir directory. Named parameters in the query
+(:name) are bound with --param name=value. For example:
+
+sql-to-nosql migrate-query recipients.sql --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+
+		bDryRun, err := c.Flags().GetBool("dry-run")
+		if err != nil {
+			return fmt.Errorf("error parsing argument dry-run: %s", err)
+		}
+
+		sqlDir, err := c.Flags().GetString("sql-dir")
+		if err != nil {
+			return fmt.Errorf("error parsing argument sql-dir: %s", err)
+		}
+
+		pkCol, err := c.Flags().GetString("pk")
+		if err != nil {
+			return fmt.Errorf("error parsing argument pk: %s", err)
+		}
+
+		skCol, err := c.Flags().GetString("sk")
+		if err != nil {
+			return fmt.Errorf("error parsing argument sk: %s", err)
+		}
+
+		strParams, err := c.Flags().GetStringToString("param")
+		if err != nil {
+			return fmt.Errorf("error parsing argument param: %s", err)
+		}
+		params := make(map[string]any, len(strParams))
+		for name, value := range strParams {
+			params[name] = value
+		}
+
+		fileName, err := queryFile(args[0], sqlDir)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel, err := cmd.CommandContext(c)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
+		relDBH, err := cmd.SourceModel(ctx, c)
+		if err != nil {
+			return err
+		}
+		defer relDBH.Release()
+
+		_, rows, err := relDBH.QueryFile(ctx, fileName, params)
+		if err != nil {
+			return fmt.Errorf("error running query in %s: %s", fileName, err)
+		}
+
+		items := make([]map[string]types.AttributeValue, 0, len(rows))
+		for i, row := range rows {
+			item, err := model.RowItem(row, pkCol, skCol)
+			if err != nil {
+				return fmt.Errorf("error converting row %d: %s", i+1, err)
+			}
+			items = append(items, item)
+		}
+
+		if bDryRun {
+			jsonBytes, err := json.MarshalIndent(&rows, "", "\t")
+			if err != nil {
+				return fmt.Errorf("error marshaling rows: %s", err)
+			}
+			fmt.Println("Rows: ", string(jsonBytes))
+			return nil
+		}
+
+		table, err := cmd.TargetTable(c)
+		if err != nil {
+			return err
+		}
+		defer table.DeadLetters.Close()
+
+		entity := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+		result, err := table.AddItemBatch(ctx, entity, items, len(items))
+		cmd.ReportDeadLetters(table)
+		fmt.Printf("Inserted %d of %d rows (%d failed, %d remaining)\n",
+			result.Written, len(items), result.Failed, result.Remaining)
+		if err != nil {
+			return fmt.Errorf("error adding rows of %s: %w", fileName, err)
+		}
+
+		return nil
+	},
+}
+
+// queryFile resolves name as given, falling back to the same name inside
+// sqlDir.
+func queryFile(name, sqlDir string) (string, error) {
+
+	if _, err := os.Stat(name); err == nil {
+		return name, nil
+	}
+
+	if !filepath.IsAbs(name) {
+		inDir := filepath.Join(sqlDir, name)
+		if _, err := os.Stat(inDir); err == nil {
+			return inDir, nil
+		}
+	}
+
+	return "", fmt.Errorf("query file %s not found (also looked in %s)", name, sqlDir)
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(migrateQueryCmd)
+
+	migrateQueryCmd.Flags().BoolP("dry-run", "d", false, "Dump rows, dont insert")
+	migrateQueryCmd.Flags().Bool("snapshot", false, "Run the query on a consistent mysql snapshot")
+	migrateQueryCmd.Flags().String("dead-letter", "dead-letters.jsonl", "Where to record items that could not be written")
+	cmd.AddTimeoutFlag(migrateQueryCmd)
+	migrateQueryCmd.Flags().String("sql-dir", "sql", "Directory to look up query files in")
+	migrateQueryCmd.Flags().String("pk", "PK", "Column to use as the partition key")
+	migrateQueryCmd.Flags().String("sk", "SK", "Column to use as the sort key")
+	migrateQueryCmd.Flags().StringToStringP("param", "P", nil, "Named query parameter as name=value (repeatable)")
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/images"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/migrator"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/quality"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+	"github.com/spf13/cobra"
+)
+
+// AddMigrationFlags adds the flags understood by RunMigrations to a
+// migration command.
+func AddMigrationFlags(c *cobra.Command) {
+	c.Flags().BoolP("dry-run", "d", false, "Dump source data, dont insert")
+	c.Flags().Bool("snapshot", false, "Read all source data from one consistent mysql snapshot")
+	c.Flags().String("progress-file", "migration-progress.json", "Where to record how far each entity got")
+	c.Flags().String("dead-letter", "dead-letters.jsonl", "Where to record items that could not be written")
+	c.Flags().String("invalid-rows", string(quality.PolicyDefault), "What to do with source rows that break a rule: default (repair if possible) or skip")
+	c.Flags().String("quality-report", "data-quality.json", "Where to record every source row that broke a rule")
+	c.Flags().String("naming", string(model.NamingCamel), "Attribute names to store: camel, source (mysql columns), short or field (Go fields)")
+	c.Flags().String("category-order", string(reldb.CategoryOrderRank), "Order of category children: rank, name or id")
+	c.Flags().String("sku-overrides", "", "JSON file of SKU prices, cDefault and cStock overrides keyed by SKU id")
+	c.Flags().Bool("image-meta", false, "Store the size, dimensions, format and hash of product and category images")
+	c.Flags().String("image-root", "", "Local document root to read images from, dynDocRoot of the configuration by default")
+	c.Flags().StringToString("overflow", nil, "Per entity strategy for items over 400KB: none, compress or split, e.g. product=split (default split for category, compress otherwise)")
+	AddTimeoutFlag(c)
+}
+
+// SourceModel connects to mysql. If the command was run with --snapshot
+// the returned model reads from a consistent snapshot and the caller must
+// Release it when done. The category order and SKU overrides are set if
+// the command has the --category-order and --sku-overrides flags.
+func SourceModel(ctx context.Context, c *cobra.Command) (*reldb.Model, error) {
+
+	bSnapshot, err := c.Flags().GetBool("snapshot")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing argument snapshot: %s", err)
+	}
+
+	cfg, err := reldb.Configuration()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching configuration: %s", err)
+	}
+
+	relDBH, err := reldb.NewModel(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to database: %s", err)
+	}
+
+	if c.Flags().Lookup("category-order") != nil {
+		strOrder, err := c.Flags().GetString("category-order")
+		if err != nil {
+			relDBH.Close()
+			return nil, fmt.Errorf("error parsing argument category-order: %s", err)
+		}
+		if relDBH.CategoryOrder, err = reldb.ParseCategoryOrder(strOrder); err != nil {
+			relDBH.Close()
+			return nil, err
+		}
+	}
+
+	if c.Flags().Lookup("sku-overrides") != nil {
+		overridesFile, err := c.Flags().GetString("sku-overrides")
+		if err != nil {
+			relDBH.Close()
+			return nil, fmt.Errorf("error parsing argument sku-overrides: %s", err)
+		}
+		if overridesFile != "" {
+			if relDBH.SKUOverrides, err = reldb.ReadSKUOverrides(overridesFile); err != nil {
+				relDBH.Close()
+				return nil, err
+			}
+		}
+	}
+
+	if !bSnapshot {
+		return relDBH, nil
+	}
+
+	snapshot, err := relDBH.Snapshot(ctx)
+	if err != nil {
+		relDBH.Close()
+		return nil, fmt.Errorf("error starting consistent snapshot: %s", err)
+	}
+
+	return snapshot, nil
+}
+
+// TargetTable returns the table to write to, with failed items going to
+// the file named by the --dead-letter flag. The caller must close the
+// table's dead letters when done.
+func TargetTable(c *cobra.Command) (*model.TableBasics, error) {
+
+	deadLetterFile, err := c.Flags().GetString("dead-letter")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing argument dead-letter: %s", err)
+	}
+
+	table, err := model.NewTableBasics()
+	if err != nil {
+		return nil, err
+	}
+	table.DeadLetters = model.NewDeadLetters(deadLetterFile)
+
+	return table, nil
+}
+
+// ReportDeadLetters tells the user where the failed items of a run went.
+func ReportDeadLetters(table *model.TableBasics) {
+
+	if count := table.DeadLetters.Count(); count > 0 {
+		fmt.Printf("%d failed items recorded in %s, retry them with: sql-to-nosql replay %s\n",
+			count, table.DeadLetters.FileName(), table.DeadLetters.FileName())
+	}
+}
+
+// RunMigrations runs the migrators in order with one database connection
+// and one dynamodb client, printing a combined summary at the end.
+func RunMigrations(c *cobra.Command, migrators []migrator.Migrator) error {
+
+	bDryRun, err := c.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("error parsing argument dry-run: %s", err)
+	}
+
+	progressFile, err := c.Flags().GetString("progress-file")
+	if err != nil {
+		return fmt.Errorf("error parsing argument progress-file: %s", err)
+	}
+
+	strPolicy, err := c.Flags().GetString("invalid-rows")
+	if err != nil {
+		return fmt.Errorf("error parsing argument invalid-rows: %s", err)
+	}
+	policy, err := quality.ParsePolicy(strPolicy)
+	if err != nil {
+		return err
+	}
+
+	qualityFile, err := c.Flags().GetString("quality-report")
+	if err != nil {
+		return fmt.Errorf("error parsing argument quality-report: %s", err)
+	}
+
+	strNaming, err := c.Flags().GetString("naming")
+	if err != nil {
+		return fmt.Errorf("error parsing argument naming: %s", err)
+	}
+	if model.ItemNaming, err = model.ParseNaming(strNaming); err != nil {
+		return err
+	}
+
+	strOverflow, err := c.Flags().GetStringToString("overflow")
+	if err != nil {
+		return fmt.Errorf("error parsing argument overflow: %s", err)
+	}
+	overflow := make(map[string]model.OverflowStrategy, len(strOverflow))
+	for entity, strStrategy := range strOverflow {
+		if overflow[entity], err = model.ParseOverflowStrategy(strStrategy); err != nil {
+			return fmt.Errorf("error in overflow strategy for %s: %s", entity, err)
+		}
+	}
+
+	enricher, err := imageEnricher(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel, err := CommandContext(c)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	relDBH, err := SourceModel(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer relDBH.Release()
+
+	table, err := TargetTable(c)
+	if err != nil {
+		return err
+	}
+	defer table.DeadLetters.Close()
+
+	report := &quality.Report{}
+	sizes := &model.SizeReport{}
+	summary, err := migrator.Run(ctx, relDBH, table, migrators, migrator.Options{
+		DryRun:   bDryRun,
+		Out:      os.Stdout,
+		Policy:   policy,
+		Quality:  report,
+		Overflow: overflow,
+		Sizes:    sizes,
+		Images:   enricher,
+	})
+	summary.Print(os.Stdout)
+	ReportDeadLetters(table)
+	sizes.Print(os.Stdout)
+	if enricher != nil {
+		fmt.Println(enricher.Summary())
+	}
+
+	report.Print(os.Stdout)
+	if qualityFile != "" && len(report.Violations) > 0 {
+		if err := report.WriteJSON(qualityFile); err != nil {
+			return err
+		}
+		fmt.Println("Data quality report recorded in", qualityFile)
+	}
+
+	if !bDryRun && progressFile != "" {
+		if err := summary.WriteProgress(progressFile); err != nil {
+			return err
+		}
+		fmt.Println("Progress recorded in", progressFile)
+	}
+
+	return err
+}
+
+// imageEnricher returns the enricher of the --image-meta flag, nil when
+// image metadata is not wanted. Images are resolved like images check does.
+func imageEnricher(c *cobra.Command) (*images.Enricher, error) {
+
+	bImageMeta, err := c.Flags().GetBool("image-meta")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing argument image-meta: %s", err)
+	}
+	if !bImageMeta {
+		return nil, nil
+	}
+
+	cfg, err := reldb.Configuration()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching configuration: %s", err)
+	}
+
+	resolver := images.Resolver{}
+	if resolver.Root, err = c.Flags().GetString("image-root"); err != nil {
+		return nil, fmt.Errorf("error parsing argument image-root: %s", err)
+	}
+	if resolver.Root == "" {
+		resolver.Root = cfg.DynDocRoot
+	}
+	if resolver.Root == "" {
+		return nil, fmt.Errorf("no document root, set dynDocRoot in the configuration or use --image-root")
+	}
+	if cfg.Remote.ImageRoot != "" {
+		resolver.Prefixes = []string{cfg.Remote.ImageRoot}
+	}
+
+	return images.NewEnricher(resolver), nil
+}
@@ -4,13 +4,11 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package product
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
-	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
-	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/migrator"
 	"github.com/spf13/cobra"
 )
 
@@ -18,30 +16,57 @@ import (
 var productCmd = &cobra.Command{
 	Use:   "product",
 	Short: "Transfer Mario Products from mysql to dynamodb",
-	RunE: func(cmd *cobra.Command, args []string) error {
+	Long: `Transfers all products, each with its attributes and SKUs, and then
+every SKU as its own item keyed SKU#<sku id> / PROD#<product id>.`,
+	RunE: func(c *cobra.Command, args []string) error {
 
-		iProdID, err := cmd.Flags().GetUint32("iProdID")
+		iProdID, err := c.Flags().GetUint32("iProdID")
 		if err != nil {
 			return fmt.Errorf("error parsing argument iProdID %d: %s", iProdID, err)
 		}
 		fmt.Println("product called with product id", iProdID)
 
-		cfg, err := reldb.Configuration()
+		showProducts, err := c.Flags().GetBool("show-products")
 		if err != nil {
-			return fmt.Errorf("error fetching configuration: %s", err)
+			return fmt.Errorf("error parsing show-products: %s", err)
+		}
+		showAttribs, err := c.Flags().GetBool("show-attributes")
+		if err != nil {
+			return fmt.Errorf("error parsing show-attributes: %s", err)
+		}
+		showSKUs, err := c.Flags().GetBool("show-skus")
+		if err != nil {
+			return fmt.Errorf("error parsing show-skus option: %s", err)
+		}
+
+		// The migration opens its own model
+		if !showProducts && !showAttribs && !showSKUs {
+			migrators := []migrator.Migrator{}
+			for _, name := range []string{"product", "sku"} {
+				m, err := migrator.Lookup(name)
+				if err != nil {
+					return err
+				}
+				migrators = append(migrators, m)
+			}
+
+			return cmd.RunMigrations(c, migrators)
 		}
 
-		relDBH, err := reldb.NewModel(cfg)
+		ctx, cancel, err := cmd.CommandContext(c)
 		if err != nil {
-			return fmt.Errorf("error connecting to database: %s", err)
+			return err
 		}
+		defer cancel()
 
-		showProducts, err := cmd.Flags().GetBool("show-products")
+		relDBH, err := cmd.SourceModel(ctx, c)
 		if err != nil {
-			return fmt.Errorf("error parsing show-products: %s", err)
+			return err
 		}
+		defer relDBH.Release()
+
 		if showProducts {
-			products, err := relDBH.Products()
+			products, err := relDBH.Products(ctx)
 			if err != nil {
 				return err
 			}
@@ -53,12 +78,8 @@ var productCmd = &cobra.Command{
 			return nil
 		}
 
-		showAttribs, err := cmd.Flags().GetBool("show-attributes")
-		if err != nil {
-			return fmt.Errorf("error parsing show-attributes: %s", err)
-		}
 		if showAttribs {
-			attribs, err := relDBH.ProductAttributes(iProdID, false)
+			attribs, err := relDBH.ProductAttributes(ctx, iProdID, false)
 			if err != nil {
 				return fmt.Errorf("error fetching product attributes for %d: %s", iProdID, err)
 			}
@@ -70,52 +91,17 @@ var productCmd = &cobra.Command{
 			return nil
 		}
 
-		showSKUs, err := cmd.Flags().GetBool("show-skus")
-		if err != nil {
-			return fmt.Errorf("error parsing show-skus option: %s", err)
-		}
-		if showSKUs {
-			productSKUs, err := relDBH.ProductSKUs(iProdID)
-			if err != nil {
-				return fmt.Errorf("error fetching product skus for product %d: %s", iProdID, err)
-			}
-			jsonBytesAttribs, err := json.MarshalIndent(&productSKUs, "", "\t")
-			if err != nil {
-				return fmt.Errorf("error marshalling product attributes: %s", err)
-			}
-			fmt.Println(
-				"SKUs: ", string(jsonBytesAttribs),
-			)
-		}
-
-		products, err := relDBH.Products()
+		productSKUs, err := relDBH.ProductSKUs(ctx, iProdID)
 		if err != nil {
-			return fmt.Errorf("error fetching all products: %s", err)
-		}
-
-		for i := range products {
-
-			iProdID := products[i].IProdID
-			attribs, err := relDBH.ProductAttributes(iProdID, false)
-			if err != nil {
-				return fmt.Errorf("error fetching product attributes for %d: %s", iProdID, err)
-			}
-			products[i].Attributes = attribs
-
-			skus, err := relDBH.ProductSKUs(iProdID)
-			if err != nil {
-				return fmt.Errorf("error fetching product skus for product %d: %s", iProdID, err)
-			}
-			products[i].SKUs = skus
+			return fmt.Errorf("error fetching product skus for product %d: %s", iProdID, err)
 		}
-
-		batchSize := 3000
-		count, err := model.AddProductBatch(context.TODO(), products, batchSize)
+		jsonBytesAttribs, err := json.MarshalIndent(&productSKUs, "", "\t")
 		if err != nil {
-			return fmt.Errorf("error adding products to dynamodb: %s", err)
+			return fmt.Errorf("error marshalling product attributes: %s", err)
 		}
-
-		fmt.Printf("Inserted %d products\n", count)
+		fmt.Println(
+			"SKUs: ", string(jsonBytesAttribs),
+		)
 
 		return nil
 	},
@@ -136,6 +122,7 @@ func init() {
 	productCmd.Flags().Uint32P("iProdID", "i", 0, "Display attributes of product with <id>")
 
 	productCmd.Flags().BoolP("show-products", "p", false, "Dump products")
-	productCmd.Flags().BoolP("show-skus", "s", false, "Dump product SKUs")
+	productCmd.Flags().BoolP("show-skus", "s", false, "Dump product SKUs with their ids")
 	productCmd.Flags().BoolP("show-attributes", "a", false, "Dump product attributes")
+	cmd.AddMigrationFlags(productCmd)
 }
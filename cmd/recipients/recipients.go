@@ -4,44 +4,23 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package recipients
 
 import (
-	"fmt"
-
 	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
-	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/migrator"
 	"github.com/spf13/cobra"
 )
 
 // recipientsCmd represents the recipients command
 var recipientsCmd = &cobra.Command{
 	Use:   "recipients",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-
-		fmt.Println("recipients called")
-
-		cfg, err := reldb.Configuration()
-		if err != nil {
-			return err
-		}
-
-		m, err := reldb.NewModel(cfg)
-		if err != nil {
-			return err
-		}
+	Short: "Transfer mailing list recipients from mysql to dynamodb",
+	RunE: func(c *cobra.Command, args []string) error {
 
-		rcpts, err := m.Recipients()
+		m, err := migrator.Lookup("recipients")
 		if err != nil {
 			return err
 		}
-		fmt.Println(rcpts)
 
-		return nil
+		return cmd.RunMigrations(c, []migrator.Migrator{m})
 	},
 }
 
@@ -56,5 +35,5 @@ func init() {
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
-	// recipientsCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	cmd.AddMigrationFlags(recipientsCmd)
 }
@@ -0,0 +1,127 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package replay
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/migrator"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// replayCmd represents the replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay <dead-letter-file>",
+	Short: "Retry the items recorded in a dead letter file",
+	Long: `Writes exactly the items recorded in a dead letter file by a previous run.
+
+Items that fail again are recorded in the file given by --dead-letter.
+Letters without an item (rows that could not be converted at all) cannot
+be replayed; they are listed so the entity can be migrated again once the
+source row is fixed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+
+		letters, err := model.ReadDeadLetters(args[0])
+		if err != nil {
+			return err
+		}
+
+		// Replay entities in the order they first appear in the file
+		entities := []string{}
+		byEntity := map[string][]map[string]types.AttributeValue{}
+		notReplayable := []model.DeadLetter{}
+		for _, letter := range letters {
+			if letter.Item == nil {
+				notReplayable = append(notReplayable, letter)
+				continue
+			}
+			if _, seen := byEntity[letter.Entity]; !seen {
+				entities = append(entities, letter.Entity)
+			}
+			byEntity[letter.Entity] = append(byEntity[letter.Entity], letter.Item)
+		}
+
+		for _, letter := range notReplayable {
+			fmt.Printf("Not replayable: %s %s/%s: %s\n", letter.Entity, letter.PK, letter.SK, letter.Error)
+		}
+
+		bDryRun, err := c.Flags().GetBool("dry-run")
+		if err != nil {
+			return fmt.Errorf("error parsing argument dry-run: %s", err)
+		}
+		if bDryRun {
+			for _, entity := range entities {
+				fmt.Printf("Would replay %d %s items\n", len(byEntity[entity]), entity)
+			}
+			return nil
+		}
+
+		ctx, cancel, err := cmd.CommandContext(c)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
+		table, err := cmd.TargetTable(c)
+		if err != nil {
+			return err
+		}
+		defer table.DeadLetters.Close()
+
+		// Replay the other entities after a failed write, but fail the
+		// command with the first error
+		var firstErr error
+		summary := migrator.Summary{}
+		for i, entity := range entities {
+			items := byEntity[entity]
+			result, err := table.AddItemBatch(ctx, entity, items, len(items))
+			status := migrator.StatusDone
+			switch {
+			case ctx.Err() != nil:
+				status = migrator.StatusInterrupted
+			case err != nil:
+				status = migrator.StatusFailed
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			summary = append(summary, migrator.Result{
+				Name:        entity,
+				Status:      status,
+				Items:       len(items),
+				BatchResult: result,
+				Counted:     true,
+				Err:         err,
+			})
+			if ctx.Err() != nil {
+				for _, notStarted := range entities[i+1:] {
+					summary = append(summary, migrator.Result{Name: notStarted, Status: migrator.StatusNotStarted})
+				}
+				break
+			}
+		}
+		summary.Print(os.Stdout)
+		cmd.ReportDeadLetters(table)
+
+		if summary.Interrupted() {
+			return context.Cause(ctx)
+		}
+
+		return firstErr
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().BoolP("dry-run", "d", false, "List what would be replayed, dont insert")
+	replayCmd.Flags().String("dead-letter", "replay-dead-letters.jsonl", "Where to record items that fail again")
+	cmd.AddTimeoutFlag(replayCmd)
+}
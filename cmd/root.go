@@ -4,7 +4,12 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 )
@@ -18,15 +23,65 @@ var RootCmd = &cobra.Command{
 	// Run: func(cmd *cobra.Command, args []string) { },
 }
 
+// ExitInterrupted is the exit status when a command was stopped by SIGINT
+// or SIGTERM, as opposed to 1 for a command that failed on its own.
+const ExitInterrupted = 130
+
+// ErrInterrupted is the cause of the command context being cancelled by a
+// signal.
+var ErrInterrupted = errors.New("interrupted")
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// The context handed to the commands is cancelled on the first SIGINT or
+// SIGTERM, with ErrInterrupted as the cause. A second signal kills the
+// process outright.
 func Execute() {
-	err := RootCmd.Execute()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-sigs
+		signal.Stop(sigs)
+		fmt.Fprintf(os.Stderr, "\nReceived %s, finishing in-flight work (send again to abort)\n", sig)
+		cancel(fmt.Errorf("%w by %s", ErrInterrupted, sig))
+	}()
+
+	err := RootCmd.ExecuteContext(ctx)
 	if err != nil {
+		if errors.Is(context.Cause(ctx), ErrInterrupted) {
+			os.Exit(ExitInterrupted)
+		}
 		os.Exit(1)
 	}
 }
 
+// AddTimeoutFlag adds the --timeout flag read by CommandContext.
+func AddTimeoutFlag(c *cobra.Command) {
+	c.Flags().Duration("timeout", 0, "Give up after this long, e.g. 90s or 10m (0 waits forever)")
+}
+
+// CommandContext returns the context of c, bounded by its --timeout flag
+// when one was given. The cancel function must always be called.
+func CommandContext(c *cobra.Command) (context.Context, context.CancelFunc, error) {
+
+	timeout, err := c.Flags().GetDuration("timeout")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing argument timeout: %s", err)
+	}
+
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(c.Context())
+		return ctx, cancel, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), timeout)
+	return ctx, cancel, nil
+}
+
 func init() {
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
@@ -0,0 +1,75 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the attribute names stored for each naming policy",
+	Long: `Prints the mapping from the fields of the category, product and recipient
+items to the attribute names stored in DynamoDB under each --naming policy
+of the migration commands. PK and SK are never renamed. Nil and empty
+values are not stored at all, so readers must treat a missing attribute
+as empty.`,
+	RunE: func(c *cobra.Command, args []string) error {
+
+		strNaming, err := c.Flags().GetString("naming")
+		if err != nil {
+			return fmt.Errorf("error parsing argument naming: %s", err)
+		}
+		naming := model.Naming("")
+		if strNaming != "" {
+			if naming, err = model.ParseNaming(strNaming); err != nil {
+				return err
+			}
+		}
+
+		bJSON, err := c.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("error parsing argument json: %s", err)
+		}
+
+		if bJSON {
+			jsonBytes, err := json.MarshalIndent(model.AttributeNames, "", "\t")
+			if err != nil {
+				return fmt.Errorf("error marshaling schema: %s", err)
+			}
+			fmt.Println(string(jsonBytes))
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		if naming == "" {
+			fmt.Fprintln(tw, "FIELD\tCAMEL\tSOURCE\tSHORT\tDESCRIPTION")
+			for _, a := range model.AttributeNames {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", a.Field, a.Camel, a.Source, a.Short, a.Description)
+			}
+		} else {
+			fmt.Fprintln(tw, "FIELD\tATTRIBUTE\tDESCRIPTION")
+			for _, a := range model.AttributeNames {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", a.Field, a.Name(naming), a.Description)
+			}
+		}
+
+		return tw.Flush()
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(schemaCmd)
+
+	schemaCmd.Flags().String("naming", "", "Only show the names of this policy: camel, source, short or field")
+	schemaCmd.Flags().Bool("json", false, "Print the mapping as JSON")
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/tree"
+	"github.com/spf13/cobra"
+)
+
+// treeDiffCmd represents the tree diff command
+var treeDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the category tree in MySQL with the one stored in DynamoDB",
+	Long: `Builds the category tree from MySQL and from the category items of the
+table and reports the categories that were added, removed, moved to another
+parent or renamed since the last migration, as a marked up tree or as json.
+Use the --naming the categories were migrated with.`,
+	RunE: func(c *cobra.Command, args []string) error {
+
+		strFormat, err := c.Flags().GetString("format")
+		if err != nil {
+			return fmt.Errorf("error parsing argument format: %s", err)
+		}
+		format, err := tree.ParseFormat(strFormat)
+		if err != nil {
+			return err
+		}
+		if format != tree.FormatText && format != tree.FormatJSON {
+			return fmt.Errorf("tree diff supports only text and json, not %s", format)
+		}
+
+		strNaming, err := c.Flags().GetString("naming")
+		if err != nil {
+			return fmt.Errorf("error parsing argument naming: %s", err)
+		}
+		if model.ItemNaming, err = model.ParseNaming(strNaming); err != nil {
+			return err
+		}
+
+		bChangesOnly, err := c.Flags().GetBool("changes-only")
+		if err != nil {
+			return fmt.Errorf("error parsing argument changes-only: %s", err)
+		}
+
+		cfg, err := reldb.Configuration()
+		if err != nil {
+			return fmt.Errorf("error fetching configuration: %s", err)
+		}
+
+		relDBH, err := reldb.NewModel(cfg)
+		if err != nil {
+			return fmt.Errorf("error connecting to database: %s", err)
+		}
+
+		table, err := model.NewTableBasics()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel, err := cmd.CommandContext(c)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
+		source, err := relDBH.CategoryTree(ctx)
+		if err != nil {
+			return fmt.Errorf("error fetching categories in cmd: %s", err)
+		}
+
+		stored, err := table.StoredCategories(ctx)
+		if err != nil {
+			return fmt.Errorf("error fetching stored categories: %s", err)
+		}
+
+		return tree.Compare(source, stored).Render(os.Stdout, format, bChangesOnly)
+	},
+}
+
+func init() {
+	treeCmd.AddCommand(treeDiffCmd)
+
+	treeDiffCmd.Flags().String("format", string(tree.FormatText), "Output format: text or json")
+	treeDiffCmd.Flags().String("naming", string(model.NamingCamel), "Attribute names the categories were stored with: camel, source, short or field")
+	treeDiffCmd.Flags().Bool("changes-only", false, "Leave out branches without changes")
+	cmd.AddTimeoutFlag(treeDiffCmd)
+}
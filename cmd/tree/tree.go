@@ -5,9 +5,13 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
 
 	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
 	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/tree"
 	"github.com/spf13/cobra"
 )
 
@@ -15,9 +19,12 @@ import (
 var treeCmd = &cobra.Command{
 	Use:   "tree",
 	Short: "Generate the Category Tree",
-	RunE: func(cmd *cobra.Command, args []string) error {
+	Long: `Prints the category tree, or the subtree under --root, at any depth as
+text, json, Graphviz dot or Mermaid, e.g.
+
+  tree --format dot --show-ids | dot -Tsvg > categories.svg`,
+	RunE: func(c *cobra.Command, args []string) error {
 
-		fmt.Println("tree called")
 		cfg, err := reldb.Configuration()
 		if err != nil {
 			return fmt.Errorf("error fetching configuration: %s", err)
@@ -28,29 +35,88 @@ var treeCmd = &cobra.Command{
 			return fmt.Errorf("error connecting to database: %s", err)
 		}
 
-		categories, err := relDBH.CategoryTree()
+		strOrder, err := c.Flags().GetString("sort")
+		if err != nil {
+			return fmt.Errorf("error parsing argument sort: %s", err)
+		}
+		if relDBH.CategoryOrder, err = reldb.ParseCategoryOrder(strOrder); err != nil {
+			return err
+		}
+
+		ctx, cancel, err := cmd.CommandContext(c)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
+		categories, err := relDBH.CategoryTree(ctx)
 		if err != nil {
 			return fmt.Errorf("error fetching categories in cmd: %s", err)
 		}
 
-		root := categories[0]
-		for _, cat1 := range root.Children {
-			fmt.Println(cat1.VName)
-			for _, cat2 := range cat1.Children {
-				fmt.Println("\t", cat2.VName)
-				for _, cat3 := range cat2.Children {
-					fmt.Println("\t\t", cat3.VName)
-					for _, cat4 := range cat3.Children {
-						fmt.Println("\t\t\t", cat4.VName)
-					}
-				}
-			}
+		bPaths, err := c.Flags().GetBool("paths")
+		if err != nil {
+			return fmt.Errorf("error parsing argument paths: %s", err)
+		}
+		if bPaths {
+			printPaths(categories)
+			return nil
+		}
+
+		strFormat, err := c.Flags().GetString("format")
+		if err != nil {
+			return fmt.Errorf("error parsing argument format: %s", err)
+		}
+		format, err := tree.ParseFormat(strFormat)
+		if err != nil {
+			return err
 		}
 
-		return nil
+		opts := tree.Options{}
+		if opts.ShowIDs, err = c.Flags().GetBool("show-ids"); err != nil {
+			return fmt.Errorf("error parsing argument show-ids: %s", err)
+		}
+		if opts.ShowStatus, err = c.Flags().GetBool("show-status"); err != nil {
+			return fmt.Errorf("error parsing argument show-status: %s", err)
+		}
+		if opts.ShowCounts, err = c.Flags().GetBool("show-counts"); err != nil {
+			return fmt.Errorf("error parsing argument show-counts: %s", err)
+		}
+
+		iRootID, err := c.Flags().GetUint32("root")
+		if err != nil {
+			return fmt.Errorf("error parsing argument root: %s", err)
+		}
+		root, err := tree.Find(&categories[0], iRootID)
+		if err != nil {
+			return err
+		}
+
+		return tree.Render(os.Stdout, root, format, opts)
 	},
 }
 
+// printPaths lists the full path of every category, sorted by path.
+func printPaths(categories []reldb.CategorySummary) {
+
+	paths := make([][]reldb.Crumb, 0, len(categories))
+	for _, category := range categories {
+		if len(category.Path) > 0 {
+			paths = append(paths, category.Path)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return reldb.PathString(paths[i], "\x00") < reldb.PathString(paths[j], "\x00")
+	})
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tPATH\tURL")
+	for _, path := range paths {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", path[len(path)-1].IPCatID, reldb.PathString(path, " > "), reldb.PathURL(path))
+	}
+	tw.Flush()
+}
+
 func init() {
 	cmd.RootCmd.AddCommand(treeCmd)
 
@@ -63,4 +129,12 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// treeCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	treeCmd.Flags().String("format", string(tree.FormatText), "Output format: text, json, dot or mermaid")
+	treeCmd.Flags().String("sort", string(reldb.CategoryOrderRank), "Order children by rank, name or id")
+	treeCmd.Flags().Bool("show-ids", false, "Show category ids")
+	treeCmd.Flags().Bool("show-status", false, "Show category status")
+	treeCmd.Flags().Bool("show-counts", false, "Show active product counts")
+	treeCmd.Flags().Uint32("root", 0, "Only print the subtree under this category id")
+	treeCmd.Flags().Bool("paths", false, "List the full path of every category instead of the tree")
+	cmd.AddTimeoutFlag(treeCmd)
 }
@@ -0,0 +1,133 @@
+package images
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Problem is what is wrong with the file of a reference.
+type Problem string
+
+const (
+	ProblemMissing Problem = "missing"
+	ProblemEmpty   Problem = "empty"
+)
+
+// Broken is a reference whose file is missing or has no bytes.
+type Broken struct {
+	Ref
+	File    string  `json:"file"`
+	Problem Problem `json:"problem"`
+}
+
+// CheckResult is the outcome of Check. Unreferenced are the image files
+// under the root, relative to it, that no reference resolves to.
+type CheckResult struct {
+	Checked      int      `json:"checked"`
+	Broken       []Broken `json:"broken"`
+	Unreferenced []string `json:"unreferenced"`
+}
+
+// Resolver maps the path of a reference to a file under Root. URLs are
+// reduced to their path, and the first of Prefixes the path starts with,
+// e.g. the remote image root, is cut off. Paths cannot leave Root.
+type Resolver struct {
+	Root     string
+	Prefixes []string
+}
+
+// Resolve returns the local file of the path of a reference.
+func (r Resolver) Resolve(refPath string) string {
+
+	if u, err := url.Parse(refPath); err == nil && u.Scheme != "" {
+		refPath = u.Path
+	}
+	refPath = path.Clean("/" + filepath.ToSlash(refPath))
+	for _, prefix := range r.Prefixes {
+		prefix = path.Clean("/" + filepath.ToSlash(prefix))
+		if prefix == "/" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(refPath, prefix+"/"); ok {
+			refPath = "/" + rest
+			break
+		}
+	}
+
+	return filepath.Join(r.Root, filepath.FromSlash(refPath))
+}
+
+// imageExtensions are the files Check counts as images when it looks for
+// unreferenced ones.
+var imageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".svg", ".bmp", ".avif"}
+
+// Check resolves the references with r and reports those whose file is
+// missing or empty. With unreferenced it also walks r.Root for image
+// files no reference resolves to.
+func Check(refs []Ref, r Resolver, unreferenced bool) (CheckResult, error) {
+
+	r.Root = filepath.Clean(r.Root)
+	result := CheckResult{Checked: len(refs), Broken: []Broken{}, Unreferenced: []string{}}
+	referenced := map[string]bool{}
+	for _, ref := range refs {
+		file := r.Resolve(ref.Path)
+		referenced[file] = true
+
+		info, err := os.Stat(file)
+		switch {
+		case err != nil && os.IsNotExist(err):
+			result.Broken = append(result.Broken, Broken{Ref: ref, File: file, Problem: ProblemMissing})
+		case err != nil:
+			return result, fmt.Errorf("error checking %s: %s", file, err)
+		case info.IsDir():
+			result.Broken = append(result.Broken, Broken{Ref: ref, File: file, Problem: ProblemMissing})
+		case info.Size() == 0:
+			result.Broken = append(result.Broken, Broken{Ref: ref, File: file, Problem: ProblemEmpty})
+		}
+	}
+
+	if !unreferenced {
+		return result, nil
+	}
+
+	err := filepath.WalkDir(r.Root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || referenced[file] || !slices.Contains(imageExtensions, strings.ToLower(filepath.Ext(file))) {
+			return nil
+		}
+		rel, err := filepath.Rel(r.Root, file)
+		if err != nil {
+			return err
+		}
+		result.Unreferenced = append(result.Unreferenced, rel)
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("error walking %s: %s", r.Root, err)
+	}
+
+	return result, nil
+}
+
+// ItemField is the field of reldb.Images a reference is stored in on the
+// item of its entity, or "" for references the items do not hold as a
+// path of their own: additional images and description tags.
+func (ref Ref) ItemField() string {
+
+	switch ref.Role {
+	case RoleSmall:
+		return "VSmallImage"
+	case RoleLarge, RoleMenu:
+		return "VImage"
+	}
+
+	return ""
+}
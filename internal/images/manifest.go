@@ -0,0 +1,279 @@
+// Package images lists the images referenced by categories and products.
+package images
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+)
+
+// Roles of a referenced image.
+const (
+	RoleSmall       = "small"
+	RoleLarge       = "large"
+	RoleMenu        = "menu"
+	RoleAdditional  = "additional"
+	RoleDescription = "description"
+)
+
+// Ref is one reference to an image. The same path referenced by several
+// entities, or in several roles, is a Duplicate in each of its refs.
+type Ref struct {
+	Path       string `json:"path"`
+	Entity     string `json:"entity"`
+	ID         uint32 `json:"id"`
+	Role       string `json:"role"`
+	AltText    string `json:"altText"`
+	Duplicate  bool   `json:"duplicate"`
+	MissingAlt bool   `json:"missingAlt"`
+}
+
+// Format is an output format of Write.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat checks a format given on the command line.
+func ParseFormat(s string) (Format, error) {
+
+	switch format := Format(s); format {
+	case FormatCSV, FormatJSON:
+		return format, nil
+	}
+
+	return "", fmt.Errorf("unknown format %q (want csv or json)", s)
+}
+
+// Manifest collects the images of the category menus, the small, large
+// and additional images of the products and the <img> tags of category
+// and product descriptions, in that order.
+func Manifest(ctx context.Context, m *reldb.Model) ([]Ref, error) {
+
+	catSummMap, err := m.CategoryMaster(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching categories: %s", err)
+	}
+	products, err := m.Products(ctx)
+	if err != nil {
+		return nil, err
+	}
+	additional, err := m.AdditionalImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := []Ref{}
+	add := func(path *string, entity string, id uint32, role string, alt *string) {
+		if path == nil || strings.TrimSpace(*path) == "" {
+			return
+		}
+		refs = append(refs, Ref{
+			Path:    strings.TrimSpace(*path),
+			Entity:  entity,
+			ID:      id,
+			Role:    role,
+			AltText: strings.TrimSpace(reldb.StringOr(alt, "")),
+		})
+	}
+
+	for _, iPCatID := range slices.Sorted(maps.Keys(catSummMap)) {
+		cat := catSummMap[iPCatID]
+		add(cat.VSmallImage, "category", iPCatID, RoleSmall, cat.VSmallImageAltTag)
+		add(cat.VImage, "category", iPCatID, RoleMenu, cat.VImageAltTag)
+		for _, img := range descriptionImages(cat.VShortDesc) {
+			add(&img.src, "category", iPCatID, RoleDescription, &img.alt)
+		}
+	}
+
+	for _, product := range products {
+		add(product.VSmallImage, "product", product.IProdID, RoleSmall, product.VSmallImageAltTag)
+		add(product.VImage, "product", product.IProdID, RoleLarge, product.VImageAltTag)
+	}
+	for _, image := range additional {
+		add(image.VName, "product", image.IProdID, RoleAdditional, image.VAltTag)
+	}
+	for _, product := range products {
+		for _, desc := range []*string{product.VShortDesc, product.VDescription} {
+			for _, img := range descriptionImages(desc) {
+				add(&img.src, "product", product.IProdID, RoleDescription, &img.alt)
+			}
+		}
+	}
+
+	flag(refs)
+
+	return refs, nil
+}
+
+// flag marks refs sharing a path as duplicates and refs without alt
+// text as missing it.
+func flag(refs []Ref) {
+
+	uses := map[string]int{}
+	for _, ref := range refs {
+		uses[ref.Path]++
+	}
+	for i := range refs {
+		refs[i].Duplicate = uses[refs[i].Path] > 1
+		refs[i].MissingAlt = refs[i].AltText == ""
+	}
+}
+
+var (
+	imgTag  = regexp.MustCompile(`(?is)<img\b[^>]*>`)
+	imgAttr = regexp.MustCompile(`(?is)\b(src|alt)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+type htmlImage struct {
+	src string
+	alt string
+}
+
+// descriptionImages finds the <img> tags of an HTML description. Entities
+// in the attributes are unescaped.
+func descriptionImages(desc *string) []htmlImage {
+
+	if desc == nil {
+		return nil
+	}
+
+	images := []htmlImage{}
+	for _, tag := range imgTag.FindAllString(*desc, -1) {
+		img := htmlImage{}
+		for _, attr := range imgAttr.FindAllStringSubmatch(tag, -1) {
+			// An unquoted value runs into the slash of <img src=a.jpg/>
+			value := html.UnescapeString(attr[2] + attr[3] + strings.TrimSuffix(attr[4], "/"))
+			switch strings.ToLower(attr[1]) {
+			case "src":
+				img.src = value
+			case "alt":
+				img.alt = value
+			}
+		}
+		images = append(images, img)
+	}
+
+	return images
+}
+
+// Summary counts the references, the distinct paths, the references
+// sharing a path and those without alt text.
+func Summary(refs []Ref) string {
+
+	paths := map[string]bool{}
+	duplicates, missingAlt := 0, 0
+	for _, ref := range refs {
+		paths[ref.Path] = true
+		if ref.Duplicate {
+			duplicates++
+		}
+		if ref.MissingAlt {
+			missingAlt++
+		}
+	}
+
+	return fmt.Sprintf("%d references to %d images, %d duplicate references, %d missing alt text",
+		len(refs), len(paths), duplicates, missingAlt)
+}
+
+var csvHeader = []string{"path", "entity", "id", "role", "alt_text", "duplicate", "missing_alt"}
+
+// Write writes the manifest to w as CSV, with a header row, or as a JSON
+// array.
+func Write(w io.Writer, refs []Ref, format Format) error {
+
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(refs); err != nil {
+			return fmt.Errorf("error marshaling image manifest: %s", err)
+		}
+		return nil
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("error writing image manifest: %s", err)
+	}
+	for _, ref := range refs {
+		record := []string{
+			ref.Path,
+			ref.Entity,
+			strconv.FormatUint(uint64(ref.ID), 10),
+			ref.Role,
+			ref.AltText,
+			strconv.FormatBool(ref.Duplicate),
+			strconv.FormatBool(ref.MissingAlt),
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("error writing image manifest: %s", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error writing image manifest: %s", err)
+	}
+
+	return nil
+}
+
+// ReadManifest reads a manifest written by Write in either format, told
+// apart by its first character, and flags it again.
+func ReadManifest(fileName string) ([]Ref, error) {
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error reading image manifest %s: %s", fileName, err)
+	}
+
+	refs := []Ref{}
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err := json.Unmarshal(data, &refs); err != nil {
+			return nil, fmt.Errorf("error decoding image manifest %s: %s", fileName, err)
+		}
+		flag(refs)
+		return refs, nil
+	}
+
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error decoding image manifest %s: %s", fileName, err)
+	}
+	for i, record := range records {
+		if i == 0 {
+			continue
+		}
+		if len(record) < len(csvHeader) {
+			return nil, fmt.Errorf("error decoding image manifest %s, line %d: want %d columns", fileName, i+1, len(csvHeader))
+		}
+		id, err := strconv.ParseUint(record[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding image manifest %s, line %d: %s", fileName, i+1, err)
+		}
+		refs = append(refs, Ref{
+			Path:    record[0],
+			Entity:  record[1],
+			ID:      uint32(id),
+			Role:    record[3],
+			AltText: record[4],
+		})
+	}
+	flag(refs)
+
+	return refs, nil
+}
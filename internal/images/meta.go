@@ -0,0 +1,103 @@
+package images
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+)
+
+// ReadMeta reads the metadata of an image file. Files the jpeg, png and
+// gif decoders do not recognize still get their size and hash.
+func ReadMeta(file string) (reldb.ImageMeta, error) {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return reldb.ImageMeta{}, err
+	}
+
+	sum := sha256.Sum256(data)
+	meta := reldb.ImageMeta{
+		IBytes:  int64(len(data)),
+		VSHA256: hex.EncodeToString(sum[:]),
+	}
+	if config, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		meta.IWidth = config.Width
+		meta.IHeight = config.Height
+		meta.VFormat = format
+	}
+
+	return meta, nil
+}
+
+// Enricher adds the ImageMeta of their image files to products and
+// categories. Every file is read once, however many items refer to it.
+type Enricher struct {
+	Resolver Resolver
+	metas    map[string]*reldb.ImageMeta
+	read     int
+	missing  int
+	unknown  int
+}
+
+// NewEnricher returns an Enricher finding files with r.
+func NewEnricher(r Resolver) *Enricher {
+	return &Enricher{Resolver: r, metas: map[string]*reldb.ImageMeta{}}
+}
+
+// Meta returns the metadata of the file of an image path, or nil if the
+// path is empty or the file cannot be read.
+func (e *Enricher) Meta(path *string) *reldb.ImageMeta {
+
+	if path == nil || strings.TrimSpace(*path) == "" {
+		return nil
+	}
+
+	file := e.Resolver.Resolve(strings.TrimSpace(*path))
+	if meta, seen := e.metas[file]; seen {
+		return meta
+	}
+
+	var meta *reldb.ImageMeta
+	if m, err := ReadMeta(file); err != nil {
+		e.missing++
+	} else {
+		e.read++
+		if m.VFormat == "" {
+			e.unknown++
+		}
+		meta = &m
+	}
+	e.metas[file] = meta
+
+	return meta
+}
+
+// ImagesMeta returns the metadata of images, or nil if no file was found.
+func (e *Enricher) ImagesMeta(images reldb.Images) *reldb.ImagesMeta {
+
+	meta := reldb.ImagesMeta{
+		VSmallImage: e.Meta(images.VSmallImage),
+		VImage:      e.Meta(images.VImage),
+	}
+	if meta.VSmallImage == nil && meta.VImage == nil {
+		return nil
+	}
+
+	return &meta
+}
+
+// Summary counts the files read, those of unknown format among them and
+// those that could not be read.
+func (e *Enricher) Summary() string {
+	return fmt.Sprintf("Image metadata: %d files read (%d of unknown format), %d not found under %s",
+		e.read, e.unknown, e.missing, e.Resolver.Root)
+}
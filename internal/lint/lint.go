@@ -0,0 +1,248 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+)
+
+// Severity says how much a lint issue matters for the migration.
+type Severity string
+
+const (
+	// SeverityError issues lose or corrupt data in the migration.
+	SeverityError Severity = "error"
+	// SeverityWarning issues migrate but produce a broken storefront.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo issues are worth a look but harmless.
+	SeverityInfo Severity = "info"
+)
+
+var severityRank = map[Severity]int{SeverityError: 0, SeverityWarning: 1, SeverityInfo: 2}
+
+// ParseSeverity checks a severity given on the command line.
+func ParseSeverity(s string) (Severity, error) {
+
+	if _, ok := severityRank[Severity(s)]; !ok {
+		return "", fmt.Errorf("unknown severity %q (want error, warning or info)", s)
+	}
+
+	return Severity(s), nil
+}
+
+// AtLeast reports whether s is as severe as min or more.
+func (s Severity) AtLeast(min Severity) bool {
+	return severityRank[s] <= severityRank[min]
+}
+
+// Check is one lint rule run against the catalog.
+type Check struct {
+	Name     string
+	Entity   string
+	Severity Severity
+	Run      func(ctx context.Context, m *reldb.Model) ([]reldb.LintFinding, error)
+}
+
+// query runs one of the lint queries of reldb.Model as a Check.
+func query(q func(*reldb.Model, context.Context) ([]reldb.LintFinding, error)) func(context.Context, *reldb.Model) ([]reldb.LintFinding, error) {
+	return func(ctx context.Context, m *reldb.Model) ([]reldb.LintFinding, error) { return q(m, ctx) }
+}
+
+// Checks are all catalog checks in the order they are reported.
+var Checks = []Check{
+	{"orphan-category", "category", SeverityError, orphanCategories},
+	{"category-cycle", "category", SeverityError, categoryCycles},
+	{"product-missing-category", "product", SeverityError, query((*reldb.Model).ProductsInMissingCategories)},
+	{"product-inactive-category", "product", SeverityWarning, query((*reldb.Model).ActiveProductsInInactiveCategories)},
+	{"duplicate-category-url", "category", SeverityWarning, query((*reldb.Model).DuplicateCategoryURLNames)},
+	{"duplicate-product-url", "product", SeverityWarning, query((*reldb.Model).DuplicateProductURLNames)},
+	{"empty-category-name", "category", SeverityError, query((*reldb.Model).CategoriesWithEmptyNames)},
+	{"empty-product-name", "product", SeverityError, query((*reldb.Model).ProductsWithEmptyNames)},
+	{"inconsistent-price", "product", SeverityWarning, query((*reldb.Model).InconsistentProductPrices)},
+	{"active-product-without-price", "product", SeverityWarning, query((*reldb.Model).ActiveProductsWithoutPrice)},
+	{"product-attribute-without-value", "product_attrib", SeverityInfo, query((*reldb.Model).ProductAttributesWithoutValues)},
+	{"category-attribute-without-value", "prodcat_attrib_dat", SeverityInfo, query((*reldb.Model).CategoryAttributesWithoutValues)},
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Check    string              `json:"check"`
+	Entity   string              `json:"entity"`
+	Severity Severity            `json:"severity"`
+	Findings []reldb.LintFinding `json:"findings"`
+}
+
+// Report is the outcome of a lint run.
+type Report []Result
+
+// Run runs every check with at least severity min.
+func Run(ctx context.Context, m *reldb.Model, min Severity) (Report, error) {
+
+	report := Report{}
+	for _, check := range Checks {
+		if !check.Severity.AtLeast(min) {
+			continue
+		}
+
+		findings, err := check.Run(ctx, m)
+		if err != nil {
+			return report, fmt.Errorf("error running check %s: %w", check.Name, err)
+		}
+
+		report = append(report, Result{
+			Check:    check.Name,
+			Entity:   check.Entity,
+			Severity: check.Severity,
+			Findings: findings,
+		})
+	}
+
+	return report, nil
+}
+
+// Count returns the number of findings with exactly severity s.
+func (r Report) Count(s Severity) int {
+
+	count := 0
+	for _, result := range r {
+		if result.Severity == s {
+			count += len(result.Findings)
+		}
+	}
+
+	return count
+}
+
+// Print writes the report grouped by severity, then check, listing every
+// flagged row ID with its detail.
+func (r Report) Print(w io.Writer) {
+
+	results := append(Report{}, r...)
+	sort.SliceStable(results, func(i, j int) bool {
+		return severityRank[results[i].Severity] < severityRank[results[j].Severity]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, result := range results {
+		if len(result.Findings) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "\n%s: %s (%d %s rows)\n", strings.ToUpper(string(result.Severity)), result.Check, len(result.Findings), result.Entity)
+		for _, finding := range result.Findings {
+			fmt.Fprintf(tw, "\t%d\t%s\n", finding.ID, finding.Detail)
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d errors, %d warnings, %d info\n",
+		r.Count(SeverityError), r.Count(SeverityWarning), r.Count(SeverityInfo))
+}
+
+// orphanCategories finds categories whose parent does not exist. CategoryTree
+// silently leaves them out of the tree.
+func orphanCategories(ctx context.Context, m *reldb.Model) ([]reldb.LintFinding, error) {
+
+	catMap, err := m.CategoryMaster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := []reldb.LintFinding{}
+	for _, iPCatID := range sortedIDs(catMap) {
+		category := catMap[iPCatID]
+		if iPCatID == 0 {
+			continue
+		}
+		if _, exists := catMap[category.IParentID]; !exists {
+			findings = append(findings, reldb.LintFinding{
+				ID:     iPCatID,
+				Detail: fmt.Sprintf("%s: parent %d does not exist", category.VName, category.IParentID),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// categoryCycles finds categories that are their own ancestor. Each cycle is
+// reported once, against its lowest category ID.
+func categoryCycles(ctx context.Context, m *reldb.Model) ([]reldb.LintFinding, error) {
+
+	catMap, err := m.CategoryMaster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := []reldb.LintFinding{}
+	reported := map[uint32]bool{}
+	for _, iPCatID := range sortedIDs(catMap) {
+
+		// Walk up from iPCatID, a cycle exists if we return to a category
+		// seen on this walk before reaching the root or an orphan.
+		path := []uint32{}
+		onPath := map[uint32]int{}
+		current := iPCatID
+		for current != 0 && !reported[current] {
+			if idx, seen := onPath[current]; seen {
+				cycle := path[idx:]
+				for _, id := range cycle {
+					reported[id] = true
+				}
+				findings = append(findings, reldb.LintFinding{
+					ID:     minID(cycle),
+					Detail: fmt.Sprintf("parent cycle %s", cyclePath(cycle)),
+				})
+				break
+			}
+			onPath[current] = len(path)
+			path = append(path, current)
+
+			category, exists := catMap[current]
+			if !exists {
+				break
+			}
+			current = category.IParentID
+		}
+	}
+
+	return findings, nil
+}
+
+func sortedIDs(catMap map[uint32]*reldb.CategorySummary) []uint32 {
+
+	ids := make([]uint32, 0, len(catMap))
+	for id := range catMap {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func minID(ids []uint32) uint32 {
+
+	min := ids[0]
+	for _, id := range ids[1:] {
+		if id < min {
+			min = id
+		}
+	}
+
+	return min
+}
+
+func cyclePath(cycle []uint32) string {
+
+	parts := make([]string, 0, len(cycle)+1)
+	for _, id := range cycle {
+		parts = append(parts, fmt.Sprint(id))
+	}
+	parts = append(parts, fmt.Sprint(cycle[0]))
+
+	return strings.Join(parts, " -> ")
+}
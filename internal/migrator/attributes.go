@@ -0,0 +1,76 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/quality"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// attributesMigrator writes the attribute master with the filter
+// configuration the LFacets of category items refer to.
+type attributesMigrator struct {
+	batchLoader
+}
+
+func init() {
+	Register(attributesMigrator{batchLoader{entity: "attributes"}})
+}
+
+func (attributesMigrator) Name() string { return "attributes" }
+
+func (attributesMigrator) Dependencies() []string { return nil }
+
+func (attributesMigrator) Extract(ctx context.Context, relDBH *reldb.Model) (any, error) {
+
+	attributes, err := relDBH.Attributes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching attributes: %s", err)
+	}
+
+	return attributes, nil
+}
+
+func (attributesMigrator) Transform(ctx context.Context, data any) ([]map[string]types.AttributeValue, []model.DeadLetter, error) {
+
+	attributes := data.([]reldb.Attribute)
+
+	items := make([]map[string]types.AttributeValue, 0, len(attributes))
+	rejected := []model.DeadLetter{}
+	for _, attrib := range attributes {
+		item, err := model.AttributeItem(attrib)
+		if err != nil {
+			pk, sk := model.AttributeKey(attrib)
+			rejected = append(rejected, model.RejectedLetter("attributes", pk, sk, err))
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return items, rejected, nil
+}
+
+// attributeRules are the checks every attribute row must pass.
+var attributeRules = []quality.Rule[reldb.Attribute]{
+	{
+		Name:  "name-required",
+		Valid: func(a reldb.Attribute) bool { return strings.TrimSpace(a.VName) != "" },
+	},
+	{
+		Name:    "status-allowed",
+		Valid:   func(a reldb.Attribute) bool { return allowedStatus[a.CStatus] },
+		Default: func(a *reldb.Attribute) { a.CStatus = "I" },
+	},
+}
+
+func (attributesMigrator) Validate(data any, policy quality.Policy, report *quality.Report) any {
+
+	id := func(a reldb.Attribute) string { return fmt.Sprintf("iAttribID=%d", a.IAttribID) }
+
+	return quality.Apply("attributes", data.([]reldb.Attribute), id, attributeRules, policy, report)
+}
@@ -0,0 +1,102 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/images"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/quality"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type categoryMigrator struct {
+	batchLoader
+}
+
+// maxCategories caps the categories a run migrates, counted before they
+// turn into category, facet and part items.
+const maxCategories = 200
+
+func init() {
+	Register(categoryMigrator{batchLoader{entity: "category"}})
+}
+
+func (categoryMigrator) Name() string { return "category" }
+
+func (categoryMigrator) Dependencies() []string { return []string{"attributes"} }
+
+func (categoryMigrator) Extract(ctx context.Context, relDBH *reldb.Model) (any, error) {
+
+	categories, err := relDBH.CategoryTreeWithFacets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching categories: %s", err)
+	}
+
+	return categories[:min(len(categories), maxCategories)], nil
+}
+
+func (categoryMigrator) Transform(ctx context.Context, data any) ([]map[string]types.AttributeValue, []model.DeadLetter, error) {
+
+	categories := data.([]reldb.CategorySummary)
+
+	items := make([]map[string]types.AttributeValue, 0, len(categories))
+	rejected := []model.DeadLetter{}
+	for _, category := range categories {
+		item, err := model.CategoryItem(category)
+		if err != nil {
+			pk, sk := model.CategoryKey(category)
+			rejected = append(rejected, model.RejectedLetter("category", pk, sk, err))
+			continue
+		}
+		items = append(items, item)
+
+		facetItem, err := model.FacetItem(category)
+		if err != nil {
+			pk, sk := model.FacetKey(category)
+			rejected = append(rejected, model.RejectedLetter("category", pk, sk, err))
+			continue
+		}
+		items = append(items, facetItem)
+	}
+
+	return items, rejected, nil
+}
+
+func (categoryMigrator) EnrichImages(data any, enricher *images.Enricher) any {
+
+	categories := data.([]reldb.CategorySummary)
+	for i := range categories {
+		categories[i].ImagesMeta = enricher.ImagesMeta(categories[i].Images)
+	}
+
+	return categories
+}
+
+// categoryRules are the checks every category row must pass. The
+// synthetic root category (iPCatID 0) has no status of its own.
+var categoryRules = []quality.Rule[reldb.CategorySummary]{
+	{
+		Name:    "status-allowed",
+		Valid:   func(c reldb.CategorySummary) bool { return c.IPCatID == 0 || allowedStatus[c.CStatus] },
+		Default: func(c *reldb.CategorySummary) { c.CStatus = "I" },
+	},
+	{
+		Name:  "name-required",
+		Valid: func(c reldb.CategorySummary) bool { return strings.TrimSpace(c.VName) != "" },
+	},
+	{
+		Name:  "url-name-required",
+		Valid: func(c reldb.CategorySummary) bool { return strings.TrimSpace(c.VURLName) != "" },
+	},
+}
+
+func (categoryMigrator) Validate(data any, policy quality.Policy, report *quality.Report) any {
+
+	id := func(c reldb.CategorySummary) string { return fmt.Sprintf("iPCatID=%d", c.IPCatID) }
+
+	return quality.Apply("category", data.([]reldb.CategorySummary), id, categoryRules, policy, report)
+}
@@ -0,0 +1,85 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/quality"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// colorMigrator writes the color master, so storefronts can list and order
+// every swatch without reading products.
+type colorMigrator struct {
+	batchLoader
+}
+
+func init() {
+	Register(colorMigrator{batchLoader{entity: "color"}})
+}
+
+func (colorMigrator) Name() string { return "color" }
+
+func (colorMigrator) Dependencies() []string { return nil }
+
+func (colorMigrator) Extract(ctx context.Context, relDBH *reldb.Model) (any, error) {
+
+	colors, err := relDBH.Colors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching colors: %s", err)
+	}
+
+	return colors, nil
+}
+
+func (colorMigrator) Transform(ctx context.Context, data any) ([]map[string]types.AttributeValue, []model.DeadLetter, error) {
+
+	colors := data.([]reldb.Color)
+
+	items := make([]map[string]types.AttributeValue, 0, len(colors))
+	rejected := []model.DeadLetter{}
+	for _, color := range colors {
+		item, err := model.ColorItem(color)
+		if err != nil {
+			pk, sk := model.ColorKey(color)
+			rejected = append(rejected, model.RejectedLetter("color", pk, sk, err))
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return items, rejected, nil
+}
+
+// swatchPattern is a hex color with or without the leading #.
+var swatchPattern = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// colorRules are the checks every color row must pass.
+var colorRules = []quality.Rule[reldb.Color]{
+	{
+		Name:  "name-required",
+		Valid: func(c reldb.Color) bool { return strings.TrimSpace(c.VName) != "" },
+	},
+	{
+		Name:    "status-allowed",
+		Valid:   func(c reldb.Color) bool { return allowedStatus[c.CStatus] },
+		Default: func(c *reldb.Color) { c.CStatus = "I" },
+	},
+	{
+		Name:    "swatch-hex",
+		Valid:   func(c reldb.Color) bool { return c.VColor == "" || swatchPattern.MatchString(c.VColor) },
+		Default: func(c *reldb.Color) { c.VColor = "" },
+	},
+}
+
+func (colorMigrator) Validate(data any, policy quality.Policy, report *quality.Report) any {
+
+	id := func(c reldb.Color) string { return fmt.Sprintf("iColorID=%d", c.IColorID) }
+
+	return quality.Apply("color", data.([]reldb.Color), id, colorRules, policy, report)
+}
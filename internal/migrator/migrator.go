@@ -0,0 +1,418 @@
+package migrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/images"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/quality"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Migrator moves one entity from mysql to dynamodb. Extract reads the
+// source rows, Transform turns them into items and Load writes the items.
+// Rows that Transform cannot turn into items are returned as dead letters
+// instead of failing the migration. Dependencies names the migrators that
+// must have run before this one.
+type Migrator interface {
+	Name() string
+	Dependencies() []string
+	Extract(ctx context.Context, relDBH *reldb.Model) (any, error)
+	Transform(ctx context.Context, data any) ([]map[string]types.AttributeValue, []model.DeadLetter, error)
+	Load(ctx context.Context, table *model.TableBasics, items []map[string]types.AttributeValue) (model.BatchResult, error)
+}
+
+// Validator is implemented by migrators that check the extracted source
+// rows before they are transformed. Validate returns the data to hand to
+// Transform, without the rows skipped under policy, and records every rule
+// a row breaks in report.
+type Validator interface {
+	Validate(data any, policy quality.Policy, report *quality.Report) any
+}
+
+// ImageEnricher is implemented by migrators whose items carry images.
+// EnrichImages returns the data to hand to Transform with the metadata
+// of the image files enricher finds.
+type ImageEnricher interface {
+	EnrichImages(data any, enricher *images.Enricher) any
+}
+
+// DependentExtractor is implemented by migrators whose rows are derived
+// from the data of a migrator they depend on, as validated and handed to
+// its Transform, instead of being read from the database again. Run calls
+// ExtractFrom with that data by migrator name in place of Extract.
+type DependentExtractor interface {
+	ExtractFrom(extracted map[string]any) (any, error)
+}
+
+var registry = map[string]Migrator{}
+
+// Register makes a migrator available by name. It is meant to be called
+// from the init function of the file defining the migrator and panics if
+// the name is already taken.
+func Register(m Migrator) {
+
+	if _, exists := registry[m.Name()]; exists {
+		panic(fmt.Sprintf("migrator %s registered twice", m.Name()))
+	}
+	registry[m.Name()] = m
+}
+
+// Lookup returns the registered migrator called name.
+func Lookup(name string) (Migrator, error) {
+
+	m, exists := registry[name]
+	if !exists {
+		return nil, fmt.Errorf("no migrator named %s", name)
+	}
+
+	return m, nil
+}
+
+// Names returns the names of all registered migrators in alphabetical order.
+func Names() []string {
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Ordered returns the named migrators, and everything they depend on, so
+// that every migrator comes after its dependencies. With no names it orders
+// all registered migrators. Ties are broken alphabetically so the order is
+// the same on every run.
+func Ordered(names ...string) ([]Migrator, error) {
+
+	if len(names) == 0 {
+		names = Names()
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[string]int{}
+	ordered := []Migrator{}
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %v", append(path, name))
+		}
+
+		m, err := Lookup(name)
+		if err != nil {
+			return err
+		}
+
+		state[name] = visiting
+		deps := append([]string{}, m.Dependencies()...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, m)
+
+		return nil
+	}
+
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// Options control a migration run.
+type Options struct {
+	DryRun   bool
+	Out      io.Writer
+	Policy   quality.Policy
+	Quality  *quality.Report
+	Overflow map[string]model.OverflowStrategy
+	Sizes    *model.SizeReport
+	Images   *images.Enricher // nil leaves out image metadata
+}
+
+// defaultOverflow holds the entities that compress cannot fit. A category
+// item nests its whole subtree in LChildren, which stays too large once
+// its strings are gzipped.
+var defaultOverflow = map[string]model.OverflowStrategy{
+	"category": model.OverflowSplit,
+}
+
+// overflow returns the overflow strategy for entity, compress unless the
+// options or defaultOverflow say otherwise.
+func (opts Options) overflow(entity string) model.OverflowStrategy {
+
+	if strategy, ok := opts.Overflow[entity]; ok {
+		return strategy
+	}
+	if strategy, ok := defaultOverflow[entity]; ok {
+		return strategy
+	}
+
+	return model.OverflowCompress
+}
+
+// Status values of a Result.
+const (
+	StatusDone        = "done"
+	StatusDryRun      = "dry-run"
+	StatusFailed      = "failed"
+	StatusInterrupted = "interrupted"
+	StatusNotStarted  = "not-started"
+)
+
+// Result records the outcome of a single migrator. The counts are only
+// known once Counted, a migrator that fails or is interrupted before its
+// items are made, or is never started, has none.
+type Result struct {
+	Name     string `json:"entity"`
+	Status   string `json:"status"`
+	Items    int    `json:"items"`
+	Rejected int    `json:"rejected"`
+	model.BatchResult
+	Duration time.Duration `json:"duration"`
+	Counted  bool          `json:"-"`
+	Err      error         `json:"-"`
+}
+
+// Summary is the combined outcome of a migration run.
+type Summary []Result
+
+// Run extracts, transforms and loads every migrator in turn using one
+// database handle and one table. A dry run dumps the extracted data as JSON
+// instead of writing it. Run stops at the first migrator that fails or is
+// interrupted, recording the ones it did not get to as not started, and
+// returns the summary so far.
+func Run(ctx context.Context, relDBH *reldb.Model, table *model.TableBasics, migrators []Migrator, opts Options) (Summary, error) {
+
+	summary := Summary{}
+	extracted := map[string]any{}
+	for i, m := range migrators {
+
+		started := time.Now()
+		result, err := run(ctx, relDBH, table, m, opts, extracted)
+		result.Duration = time.Since(started)
+		result.Err = err
+		switch {
+		case err == nil && opts.DryRun:
+			result.Status = StatusDryRun
+		case err == nil:
+			result.Status = StatusDone
+		case ctx.Err() != nil:
+			result.Status = StatusInterrupted
+		default:
+			result.Status = StatusFailed
+		}
+		summary = append(summary, result)
+
+		if err != nil {
+			for _, notStarted := range migrators[i+1:] {
+				summary = append(summary, Result{Name: notStarted.Name(), Status: StatusNotStarted})
+			}
+			return summary, fmt.Errorf("error migrating %s: %w", m.Name(), err)
+		}
+	}
+
+	return summary, nil
+}
+
+// run migrates one entity. The data handed to Transform is kept in
+// extracted for the migrators that derive their rows from it.
+func run(ctx context.Context, relDBH *reldb.Model, table *model.TableBasics, m Migrator, opts Options, extracted map[string]any) (Result, error) {
+
+	result := Result{Name: m.Name()}
+
+	var data any
+	var err error
+	if dependent, ok := m.(DependentExtractor); ok {
+		data, err = dependent.ExtractFrom(extracted)
+	} else {
+		data, err = m.Extract(ctx, relDBH)
+	}
+	if err != nil {
+		return result, fmt.Errorf("error extracting: %w", err)
+	}
+
+	if validator, ok := m.(Validator); ok {
+		data = validator.Validate(data, opts.Policy, opts.Quality)
+	}
+
+	if enricher, ok := m.(ImageEnricher); ok && opts.Images != nil {
+		data = enricher.EnrichImages(data, opts.Images)
+	}
+	extracted[m.Name()] = data
+
+	items, rejected, err := m.Transform(ctx, data)
+	if err != nil {
+		return result, fmt.Errorf("error transforming: %w", err)
+	}
+
+	items, oversized := model.FitItems(m.Name(), items, opts.overflow(m.Name()), opts.Sizes)
+	rejected = append(rejected, oversized...)
+
+	result.Items = len(items)
+	result.Remaining = len(items)
+	result.Rejected = len(rejected)
+	result.Counted = true
+
+	if !opts.DryRun {
+		for _, letter := range rejected {
+			if err := table.DeadLetters.Add(letter); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	if opts.DryRun {
+		jsonBytes, err := json.MarshalIndent(data, "", "\t")
+		if err != nil {
+			return result, fmt.Errorf("error marshaling %s: %w", m.Name(), err)
+		}
+		fmt.Fprintf(opts.Out, "%s: %s\n", m.Name(), string(jsonBytes))
+		return result, nil
+	}
+
+	result.BatchResult, err = m.Load(ctx, table, items)
+	if err != nil {
+		return result, fmt.Errorf("error loading: %w", err)
+	}
+
+	return result, nil
+}
+
+// Print writes the summary as a table. Migrators without counts show none
+// and are listed below the totals, which do not include them.
+func (s Summary) Print(w io.Writer) {
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENTITY\tSTATUS\tITEMS\tREJECTED\tWRITTEN\tFAILED\tREMAINING\tDURATION\tERROR")
+
+	var total Result
+	uncounted := []string{}
+	for _, r := range s {
+		errStr := ""
+		if r.Err != nil {
+			errStr = r.Err.Error()
+		}
+		if !r.Counted {
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\t-\t-\t%s\t%s\n", r.Name, r.Status, r.Duration.Round(time.Millisecond), errStr)
+			total.Duration += r.Duration
+			uncounted = append(uncounted, r.Name)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			r.Name, r.Status, r.Items, r.Rejected, r.Written, r.Failed, r.Remaining, r.Duration.Round(time.Millisecond), errStr)
+		total.Items += r.Items
+		total.Rejected += r.Rejected
+		total.Written += r.Written
+		total.Failed += r.Failed
+		total.Remaining += r.Remaining
+		total.Duration += r.Duration
+	}
+	fmt.Fprintf(tw, "total\t\t%d\t%d\t%d\t%d\t%d\t%s\t\n",
+		total.Items, total.Rejected, total.Written, total.Failed, total.Remaining, total.Duration.Round(time.Millisecond))
+
+	tw.Flush()
+
+	if len(uncounted) > 0 {
+		fmt.Fprintf(w, "Not in the totals, their items were never counted: %s\n", strings.Join(uncounted, ", "))
+	}
+}
+
+// Interrupted reports whether any migrator in the summary was interrupted.
+func (s Summary) Interrupted() bool {
+
+	for _, r := range s {
+		if r.Status == StatusInterrupted {
+			return true
+		}
+	}
+
+	return false
+}
+
+// progressRecord is what WriteProgress stores for every migrator. Result
+// is nil for migrators without counts, so that they are left out rather
+// than recorded as 0.
+type progressRecord struct {
+	Name   string `json:"entity"`
+	Status string `json:"status"`
+	*Result
+	Error string `json:"error,omitempty"`
+}
+
+// WriteProgress records the summary as JSON in fileName so that an
+// interrupted or failed run leaves a record of how far each entity got.
+func (s Summary) WriteProgress(fileName string) error {
+
+	records := make([]progressRecord, len(s))
+	for i, r := range s {
+		records[i] = progressRecord{Name: r.Name, Status: r.Status}
+		if r.Counted {
+			records[i].Result = &s[i]
+		}
+		if r.Err != nil {
+			records[i].Error = r.Err.Error()
+		}
+	}
+
+	progress := struct {
+		Written     time.Time        `json:"written"`
+		Interrupted bool             `json:"interrupted"`
+		Entities    []progressRecord `json:"entities"`
+	}{
+		Written:     time.Now(),
+		Interrupted: s.Interrupted(),
+		Entities:    records,
+	}
+
+	jsonBytes, err := json.MarshalIndent(progress, "", "\t")
+	if err != nil {
+		return fmt.Errorf("error marshaling progress: %w", err)
+	}
+
+	if err := os.WriteFile(fileName, jsonBytes, 0644); err != nil {
+		return fmt.Errorf("error writing progress file %s: %w", fileName, err)
+	}
+
+	return nil
+}
+
+// batchLoader is the Load shared by migrators that write their items with
+// a plain batch write. Migrators that cap how many rows they migrate do so
+// on the rows, not on the items those turn into.
+type batchLoader struct {
+	entity string
+}
+
+func (l batchLoader) Load(ctx context.Context, table *model.TableBasics, items []map[string]types.AttributeValue) (model.BatchResult, error) {
+	return table.AddItemBatch(ctx, l.entity, items, len(items))
+}
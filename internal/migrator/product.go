@@ -0,0 +1,175 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/images"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/quality"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type productMigrator struct {
+	batchLoader
+}
+
+// maxProducts caps the products a run migrates.
+const maxProducts = 3000
+
+func init() {
+	Register(productMigrator{batchLoader{entity: "product"}})
+}
+
+func (productMigrator) Name() string { return "product" }
+
+func (productMigrator) Dependencies() []string { return []string{"category"} }
+
+func (productMigrator) Extract(ctx context.Context, relDBH *reldb.Model) (any, error) {
+
+	products, err := relDBH.Products(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching all products: %s", err)
+	}
+	products = products[:min(len(products), maxProducts)]
+
+	paths, err := relDBH.CategoryPaths(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching category paths: %s", err)
+	}
+
+	for i := range products {
+
+		products[i].Path = paths[products[i].IPCatID]
+
+		iProdID := products[i].IProdID
+		attribs, err := relDBH.ProductAttributes(ctx, iProdID, false)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching product attributes for %d: %s", iProdID, err)
+		}
+		products[i].Attributes = attribs
+
+		skus, err := relDBH.ProductSKUs(ctx, iProdID)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching product skus for product %d: %s", iProdID, err)
+		}
+		products[i].SKUs = skus
+	}
+
+	return products, nil
+}
+
+func (productMigrator) Transform(ctx context.Context, data any) ([]map[string]types.AttributeValue, []model.DeadLetter, error) {
+
+	products := data.([]reldb.Product)
+
+	items := make([]map[string]types.AttributeValue, 0, len(products))
+	rejected := []model.DeadLetter{}
+	for _, product := range products {
+		item, err := model.ProductItem(product)
+		if err != nil {
+			pk, sk := model.ProductKey(product)
+			rejected = append(rejected, model.RejectedLetter("product", pk, sk, err))
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return items, rejected, nil
+}
+
+func (productMigrator) EnrichImages(data any, enricher *images.Enricher) any {
+
+	products := data.([]reldb.Product)
+	for i := range products {
+		products[i].ImagesMeta = enricher.ImagesMeta(products[i].Images)
+	}
+
+	return products
+}
+
+// productRules are the checks every product row must pass.
+var productRules = []quality.Rule[reldb.Product]{
+	{
+		Name:    "status-required",
+		Valid:   func(p reldb.Product) bool { return p.CStatus != nil },
+		Default: func(p *reldb.Product) { p.CStatus = aws.String("I") },
+	},
+	{
+		Name:    "status-allowed",
+		Valid:   func(p reldb.Product) bool { return p.CStatus == nil || allowedStatus[*p.CStatus] },
+		Default: func(p *reldb.Product) { p.CStatus = aws.String("I") },
+	},
+	{
+		Name:  "name-required",
+		Valid: func(p reldb.Product) bool { return strings.TrimSpace(p.VName) != "" },
+	},
+	{
+		Name:  "url-name-required",
+		Valid: func(p reldb.Product) bool { return strings.TrimSpace(p.VURLName) != "" },
+	},
+	{
+		Name: "prices-non-negative",
+		Valid: func(p reldb.Product) bool {
+			return p.FRetailPrice >= 0 && p.FRetailOPrice >= 0 && p.FPrice >= 0 && p.FOPrice >= 0 && p.FShipping >= 0
+		},
+		Default: func(p *reldb.Product) {
+			p.FRetailPrice = max(p.FRetailPrice, 0)
+			p.FRetailOPrice = max(p.FRetailOPrice, 0)
+			p.FPrice = max(p.FPrice, 0)
+			p.FOPrice = max(p.FOPrice, 0)
+			p.FShipping = max(p.FShipping, 0)
+		},
+	},
+	{
+		Name: "attributes-complete",
+		Valid: func(p reldb.Product) bool {
+			return !slices.ContainsFunc(p.Attributes, incompleteAttribute)
+		},
+		Default: func(p *reldb.Product) {
+			p.Attributes = slices.DeleteFunc(p.Attributes, incompleteAttribute)
+		},
+	},
+	{
+		Name: "skus-complete",
+		Valid: func(p reldb.Product) bool {
+			return !slices.ContainsFunc(p.SKUs, incompleteSKU)
+		},
+		Default: func(p *reldb.Product) {
+			p.SKUs = slices.DeleteFunc(p.SKUs, incompleteSKU)
+		},
+	},
+}
+
+// allowedStatus are the cStatus codes of active and inactive rows.
+var allowedStatus = map[string]bool{"A": true, "I": true}
+
+func incompleteAttribute(a reldb.ProductAttribute) bool {
+	return a.VAttribName == nil || a.VValue == nil
+}
+
+func incompleteSKU(sku reldb.SKU) bool {
+
+	if sku.FRetailPrice < 0 || sku.FRetailOPrice < 0 || sku.FPrice < 0 || sku.FOPrice < 0 {
+		return true
+	}
+	for _, a := range sku.Attributes {
+		if a.VAttribName == "" || a.VAttribValue == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (productMigrator) Validate(data any, policy quality.Policy, report *quality.Report) any {
+
+	id := func(p reldb.Product) string { return fmt.Sprintf("iProdID=%d", p.IProdID) }
+
+	return quality.Apply("product", data.([]reldb.Product), id, productRules, policy, report)
+}
@@ -0,0 +1,73 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/quality"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type recipientsMigrator struct {
+	batchLoader
+}
+
+func init() {
+	Register(recipientsMigrator{batchLoader{entity: "recipients"}})
+}
+
+func (recipientsMigrator) Name() string { return "recipients" }
+
+func (recipientsMigrator) Dependencies() []string { return nil }
+
+func (recipientsMigrator) Extract(ctx context.Context, relDBH *reldb.Model) (any, error) {
+
+	rcpts, err := relDBH.Recipients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching recipients: %s", err)
+	}
+
+	return rcpts, nil
+}
+
+func (recipientsMigrator) Transform(ctx context.Context, data any) ([]map[string]types.AttributeValue, []model.DeadLetter, error) {
+
+	rcpts := data.([]reldb.Recipient)
+
+	items := make([]map[string]types.AttributeValue, 0, len(rcpts))
+	rejected := []model.DeadLetter{}
+	for _, rcpt := range rcpts {
+		item, err := model.RecipientItem(rcpt)
+		if err != nil {
+			pk, sk := model.RecipientKey(rcpt)
+			rejected = append(rejected, model.RejectedLetter("recipients", pk, sk, err))
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return items, rejected, nil
+}
+
+// recipientRules are the checks every recipient row must pass.
+var recipientRules = []quality.Rule[reldb.Recipient]{
+	{
+		Name:  "set-required",
+		Valid: func(r reldb.Recipient) bool { return strings.TrimSpace(r.Set) != "" },
+	},
+	{
+		Name:  "email-required",
+		Valid: func(r reldb.Recipient) bool { return strings.Contains(r.EMail, "@") },
+	},
+}
+
+func (recipientsMigrator) Validate(data any, policy quality.Policy, report *quality.Report) any {
+
+	id := func(r reldb.Recipient) string { return fmt.Sprintf("%s/%s", r.Set, r.EMail) }
+
+	return quality.Apply("recipients", data.([]reldb.Recipient), id, recipientRules, policy, report)
+}
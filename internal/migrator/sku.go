@@ -0,0 +1,66 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/model"
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// skuMigrator writes every SKU as its own item, next to the copy in the
+// LSKUs list of its product. The SKUs are those of the products the
+// product migrator validated, so a SKU dropped from its product, or of a
+// product that was skipped or left out by the cap, is not written alone.
+type skuMigrator struct {
+	batchLoader
+}
+
+func init() {
+	Register(skuMigrator{batchLoader{entity: "sku"}})
+}
+
+func (skuMigrator) Name() string { return "sku" }
+
+func (skuMigrator) Dependencies() []string { return []string{"product"} }
+
+// Extract is not used, Run derives the SKUs with ExtractFrom.
+func (skuMigrator) Extract(ctx context.Context, relDBH *reldb.Model) (any, error) {
+	return nil, fmt.Errorf("sku items are built from the products of the product migrator")
+}
+
+func (skuMigrator) ExtractFrom(extracted map[string]any) (any, error) {
+
+	products, ok := extracted["product"].([]reldb.Product)
+	if !ok {
+		return nil, fmt.Errorf("sku items need the products of the product migrator")
+	}
+
+	skus := []reldb.SKU{}
+	for _, product := range products {
+		skus = append(skus, product.SKUs...)
+	}
+
+	return skus, nil
+}
+
+func (skuMigrator) Transform(ctx context.Context, data any) ([]map[string]types.AttributeValue, []model.DeadLetter, error) {
+
+	skus := data.([]reldb.SKU)
+
+	items := make([]map[string]types.AttributeValue, 0, len(skus))
+	rejected := []model.DeadLetter{}
+	for _, sku := range skus {
+		item, err := model.SKUItem(sku)
+		if err != nil {
+			pk, sk := model.SKUKey(sku)
+			rejected = append(rejected, model.RejectedLetter("sku", pk, sk, err))
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return items, rejected, nil
+}
@@ -0,0 +1,331 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ItemCodec converts an entity into the item stored in the table and back.
+// Decode reads items written under the current ItemNaming and undoes the
+// compress overflow strategy. Values that are not stored, nil pointers and
+// empty strings and lists, decode as their zero value. A list moved into
+// part items by the split strategy decodes as empty.
+type ItemCodec[T any] interface {
+	Encode(entity T) (map[string]types.AttributeValue, error)
+	Decode(item map[string]types.AttributeValue) (T, error)
+}
+
+var (
+	_ ItemCodec[reldb.CategorySummary] = CategoryCodec{}
+	_ ItemCodec[reldb.Product]         = ProductCodec{}
+	_ ItemCodec[reldb.SKU]             = SKUCodec{}
+	_ ItemCodec[reldb.Color]           = ColorCodec{}
+	_ ItemCodec[reldb.Attribute]       = AttributeCodec{}
+	_ ItemCodec[reldb.Order]           = OrderCodec{}
+	_ ItemCodec[reldb.Recipient]       = RecipientCodec{}
+)
+
+// CategoryCodec is the ItemCodec of categories.
+type CategoryCodec struct{}
+
+func (CategoryCodec) Encode(cat reldb.CategorySummary) (map[string]types.AttributeValue, error) {
+	return CategoryItem(cat)
+}
+
+func (CategoryCodec) Decode(item map[string]types.AttributeValue) (reldb.CategorySummary, error) {
+
+	catVal := CategoryValue{}
+	if err := decodeItem(item, &catVal); err != nil {
+		return reldb.CategorySummary{}, fmt.Errorf("error decoding category: %s", err)
+	}
+
+	return reldb.CategorySummary{
+		IPCatID:       catVal.IPCatID,
+		VName:         catVal.VCategoryName,
+		VURLName:      catVal.VCategoryURLName,
+		IParentID:     catVal.IParentID,
+		VShortDesc:    catVal.VShortDescription,
+		Images:        catVal.MImages,
+		ImagesMeta:    catVal.MImageMeta,
+		CStatus:       strings.TrimPrefix(catVal.CTypeStatus, "C"),
+		IRank:         catVal.IRank,
+		IProductCount: catVal.IProductCount,
+		ISubtreeCount: catVal.ISubtreeCount,
+		Attributes:    catVal.LAttributes,
+		Facets:        catVal.LFacets,
+		Path:          catVal.LPath,
+		Children:      catVal.LChildren,
+	}, nil
+}
+
+// ProductCodec is the ItemCodec of products.
+type ProductCodec struct{}
+
+func (ProductCodec) Encode(product reldb.Product) (map[string]types.AttributeValue, error) {
+	return ProductItem(product)
+}
+
+func (ProductCodec) Decode(item map[string]types.AttributeValue) (reldb.Product, error) {
+
+	prodVal := ProductValue{}
+	if err := decodeItem(item, &prodVal); err != nil {
+		return reldb.Product{}, fmt.Errorf("error decoding product: %s", err)
+	}
+
+	// Items written before IProdID was stored only have it in the PK
+	if prodVal.IProdID == 0 {
+		id, err := productID(prodVal.PK)
+		if err != nil {
+			return reldb.Product{}, fmt.Errorf("error decoding product: %s", err)
+		}
+		prodVal.IProdID = id
+	}
+
+	var status *string
+	if s, ok := strings.CutPrefix(prodVal.CTypeStatus, "P"); ok {
+		status = &s
+	}
+
+	return reldb.Product{
+		IProdID:          prodVal.IProdID,
+		IPCatID:          prodVal.IPCatID,
+		CCode:            prodVal.CCode,
+		VName:            prodVal.VName,
+		VCategoryName:    prodVal.VCategoryName,
+		VURLName:         prodVal.VURLName,
+		VCategoryURLName: prodVal.VCategoryURLName,
+		VShortDesc:       prodVal.VShortDescription,
+		VDescription:     prodVal.VDescription,
+		ProdPrice:        prodVal.MPrices,
+		Images:           prodVal.MImages,
+		ImagesMeta:       prodVal.MImageMeta,
+		CStatus:          status,
+		VYTID:            prodVal.VYTID,
+		Attributes:       prodVal.LAttributes,
+		SKUs:             prodVal.LSKUs,
+		Path:             prodVal.LPath,
+	}, nil
+}
+
+// SKUCodec is the ItemCodec of SKUs stored as their own item.
+type SKUCodec struct{}
+
+func (SKUCodec) Encode(sku reldb.SKU) (map[string]types.AttributeValue, error) {
+	return SKUItem(sku)
+}
+
+func (SKUCodec) Decode(item map[string]types.AttributeValue) (reldb.SKU, error) {
+
+	skuVal := SKUValue{}
+	if err := decodeItem(item, &skuVal); err != nil {
+		return reldb.SKU{}, fmt.Errorf("error decoding sku: %s", err)
+	}
+
+	return skuVal.SKU, nil
+}
+
+// ColorCodec is the ItemCodec of the color master.
+type ColorCodec struct{}
+
+func (ColorCodec) Encode(color reldb.Color) (map[string]types.AttributeValue, error) {
+	return ColorItem(color)
+}
+
+func (ColorCodec) Decode(item map[string]types.AttributeValue) (reldb.Color, error) {
+
+	colorVal := ColorValue{}
+	if err := decodeItem(item, &colorVal); err != nil {
+		return reldb.Color{}, fmt.Errorf("error decoding color: %s", err)
+	}
+
+	return colorVal.Color, nil
+}
+
+// AttributeCodec is the ItemCodec of the attribute master.
+type AttributeCodec struct{}
+
+func (AttributeCodec) Encode(attrib reldb.Attribute) (map[string]types.AttributeValue, error) {
+	return AttributeItem(attrib)
+}
+
+func (AttributeCodec) Decode(item map[string]types.AttributeValue) (reldb.Attribute, error) {
+
+	attribVal := AttributeDefValue{}
+	if err := decodeItem(item, &attribVal); err != nil {
+		return reldb.Attribute{}, fmt.Errorf("error decoding attribute: %s", err)
+	}
+
+	return attribVal.Attribute, nil
+}
+
+// OrderCodec is the ItemCodec of orders, with their products and
+// shipments.
+type OrderCodec struct{}
+
+func (OrderCodec) Encode(order reldb.Order) (map[string]types.AttributeValue, error) {
+	return OrderItem(order)
+}
+
+func (OrderCodec) Decode(item map[string]types.AttributeValue) (reldb.Order, error) {
+
+	orderVal := OrderValue{}
+	if err := decodeItem(item, &orderVal); err != nil {
+		return reldb.Order{}, fmt.Errorf("error decoding order: %s", err)
+	}
+
+	return orderVal.Order, nil
+}
+
+// RecipientCodec is the ItemCodec of mailing list recipients.
+type RecipientCodec struct{}
+
+func (RecipientCodec) Encode(rcpt reldb.Recipient) (map[string]types.AttributeValue, error) {
+	return RecipientItem(rcpt)
+}
+
+func (RecipientCodec) Decode(item map[string]types.AttributeValue) (reldb.Recipient, error) {
+
+	rcpt := reldb.Recipient{}
+	if err := decodeItem(item, &rcpt); err != nil {
+		return reldb.Recipient{}, fmt.Errorf("error decoding recipient: %s", err)
+	}
+
+	// The key is only set on the copy RecipientItem marshals
+	rcpt.PK, rcpt.SK = "", ""
+
+	return rcpt, nil
+}
+
+// OrderValue is the item of an order.
+type OrderValue struct {
+	PK string
+	SK string
+	reldb.Order
+}
+
+// OrderKey returns the PK and SK of the item of an order.
+func OrderKey(order reldb.Order) (string, string) {
+	return "Order", fmt.Sprintf("ORD#%d", order.IOrdID)
+}
+
+// OrderItem converts an order, with its products and shipments, into the
+// item stored in the table.
+func OrderItem(order reldb.Order) (map[string]types.AttributeValue, error) {
+
+	pk, sk := OrderKey(order)
+	item, err := attributevalue.MarshalMap(OrderValue{PK: pk, SK: sk, Order: order})
+	if err != nil {
+		return nil, fmt.Errorf("error converting order %d to attribute-value: %s", order.IOrdID, err)
+	}
+
+	return nameItem(item), nil
+}
+
+// fieldNames maps the attribute names of every naming policy back to the
+// fields they may come from. Some names are shared, e.g. camel "name" is
+// both VName and Name, so every candidate field is set and the struct
+// being decoded picks the one it has.
+var fieldNames = func() map[Naming]map[string][]string {
+
+	names := map[Naming]map[string][]string{}
+	for _, naming := range []Naming{NamingField, NamingCamel, NamingSource, NamingShort} {
+		names[naming] = map[string][]string{}
+		for _, a := range AttributeNames {
+			name := a.Name(naming)
+			names[naming][name] = append(names[naming][name], a.Field)
+		}
+	}
+
+	return names
+}()
+
+// decodeItem undoes compressItem and nameItem and unmarshals the result
+// into v.
+func decodeItem(item map[string]types.AttributeValue, v any) error {
+
+	item, err := decompressItem(item)
+	if err != nil {
+		return err
+	}
+
+	return attributevalue.UnmarshalMap(unnameItem(item), v)
+}
+
+func decompressItem(item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+
+	compressed, ok := item[CompressedAttribute].(*types.AttributeValueMemberSS)
+	if !ok {
+		return item, nil
+	}
+
+	decompressed := make(map[string]types.AttributeValue, len(item))
+	for name, av := range item {
+		decompressed[name] = av
+	}
+	delete(decompressed, CompressedAttribute)
+	for _, name := range compressed.Value {
+		s, err := DecompressAttribute(item[name])
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing %s: %s", name, err)
+		}
+		decompressed[name] = &types.AttributeValueMemberS{Value: s}
+	}
+
+	return decompressed, nil
+}
+
+func unnameItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+
+	fields := make(map[string]types.AttributeValue, len(item))
+	for name, av := range item {
+		av = unnameValue(av)
+		candidates, ok := fieldNames[ItemNaming][name]
+		if !ok {
+			fields[name] = av
+			continue
+		}
+		for _, field := range candidates {
+			fields[field] = av
+		}
+	}
+
+	return fields
+}
+
+func unnameValue(av types.AttributeValue) types.AttributeValue {
+
+	switch v := av.(type) {
+	case *types.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: unnameItem(v.Value)}
+	case *types.AttributeValueMemberL:
+		list := make([]types.AttributeValue, len(v.Value))
+		for i, elem := range v.Value {
+			list[i] = unnameValue(elem)
+		}
+		return &types.AttributeValueMemberL{Value: list}
+	}
+
+	return av
+}
+
+// productID recovers the product ID from the PK of a product item.
+func productID(pk string) (uint32, error) {
+
+	_, strID, ok := strings.Cut(pk, "PROD")
+	if !ok {
+		return 0, fmt.Errorf("%q is not a product key", pk)
+	}
+
+	id, err := strconv.ParseUint(strID, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a product key: %s", pk, err)
+	}
+
+	return uint32(id), nil
+}
@@ -0,0 +1,459 @@
+package model
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// The generators below only produce values a round trip can keep: string
+// pointers are nil or non-empty and lists are nil or non-empty, because
+// empty strings and lists are not stored and decode as their zero value.
+
+const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 -/#&<>"
+
+// genString returns a string that is empty now and then, and long enough
+// to be compressed now and then.
+func genString(r *rand.Rand) string {
+
+	n := r.Intn(20)
+	switch r.Intn(10) {
+	case 0:
+		n = 0
+	case 1:
+		n = compressMinSize + r.Intn(2*compressMinSize)
+	}
+
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[r.Intn(len(letters))]
+	}
+
+	return string(b)
+}
+
+func genStringPtr(r *rand.Rand) *string {
+	return ptrTo(r, genString(r))
+}
+
+// genText returns a string for the top level text attributes compressItem
+// works on, now and then one that makes the item too large on its own but
+// compresses well.
+func genText(r *rand.Rand) string {
+
+	if r.Intn(4) > 0 {
+		return genString(r)
+	}
+
+	chunk := make([]byte, 32)
+	for i := range chunk {
+		chunk[i] = letters[r.Intn(len(letters))]
+	}
+
+	return strings.Repeat(string(chunk), (MaxItemSize/2+r.Intn(MaxItemSize))/len(chunk))
+}
+
+func genTextPtr(r *rand.Rand) *string {
+	return ptrTo(r, genText(r))
+}
+
+func ptrTo(r *rand.Rand, s string) *string {
+
+	if s != "" && r.Intn(3) > 0 {
+		return &s
+	}
+
+	return nil
+}
+
+func genMoney(r *rand.Rand) reldb.Money {
+	return reldb.Money(r.Int63n(2_000_000_00) - 1_000_000_00)
+}
+
+func genTime(r *rand.Rand) time.Time {
+	return time.Unix(r.Int63n(2_000_000_000), 0).UTC()
+}
+
+// genList returns nil or one to three values of gen.
+func genList[T any](r *rand.Rand, gen func(*rand.Rand) T) []T {
+
+	n := r.Intn(4)
+	if n == 0 {
+		return nil
+	}
+
+	list := make([]T, n)
+	for i := range list {
+		list[i] = gen(r)
+	}
+
+	return list
+}
+
+func genImages(r *rand.Rand) reldb.Images {
+	return reldb.Images{
+		VSmallImage:       genStringPtr(r),
+		VSmallImageAltTag: genStringPtr(r),
+		VImage:            genStringPtr(r),
+		VImageAltTag:      genStringPtr(r),
+	}
+}
+
+func genImageMeta(r *rand.Rand) *reldb.ImageMeta {
+
+	if r.Intn(2) == 0 {
+		return nil
+	}
+
+	return &reldb.ImageMeta{
+		IWidth:  r.Intn(4000),
+		IHeight: r.Intn(4000),
+		VFormat: []string{"", "gif", "jpeg", "png"}[r.Intn(4)],
+		IBytes:  r.Int63n(1 << 30),
+		VSHA256: genString(r),
+	}
+}
+
+func genImagesMeta(r *rand.Rand) *reldb.ImagesMeta {
+
+	meta := reldb.ImagesMeta{VSmallImage: genImageMeta(r), VImage: genImageMeta(r)}
+	if meta.VSmallImage == nil && meta.VImage == nil {
+		return nil
+	}
+
+	return &meta
+}
+
+func genCrumb(r *rand.Rand) reldb.Crumb {
+	return reldb.Crumb{IPCatID: r.Uint32(), VName: genString(r), VURLName: genString(r)}
+}
+
+func genCategoryAttribute(r *rand.Rand) reldb.CategoryAttribute {
+	return reldb.CategoryAttribute{
+		IAttribDatID: r.Uint32(),
+		IPCatID:      r.Uint32(),
+		IAttribID:    r.Uint32(),
+		VAttribName:  genStringPtr(r),
+		VName:        genStringPtr(r),
+		IRank:        r.Intn(100),
+	}
+}
+
+func genCategory(r *rand.Rand, depth int) reldb.CategorySummary {
+
+	cat := reldb.CategorySummary{
+		IPCatID:       r.Uint32(),
+		VName:         genString(r),
+		VURLName:      genString(r),
+		IParentID:     r.Uint32(),
+		VShortDesc:    genStringPtr(r),
+		Images:        genImages(r),
+		CStatus:       []string{"A", "I"}[r.Intn(2)],
+		IRank:         r.Intn(100),
+		IProductCount: r.Intn(1000),
+		ISubtreeCount: r.Intn(10000),
+		Attributes:    genList(r, genCategoryAttribute),
+		Facets:        genList(r, (*rand.Rand).Uint32),
+	}
+
+	// Children are stored as they are and keep only the fields of the
+	// category that are marshaled, not those the item stores by name
+	if depth == 0 {
+		cat.VShortDesc = genTextPtr(r)
+		cat.ImagesMeta = genImagesMeta(r)
+		cat.Path = genList(r, genCrumb)
+	}
+	if depth < 2 {
+		cat.Children = genList(r, func(r *rand.Rand) *reldb.CategorySummary {
+			child := genCategory(r, depth+1)
+			return &child
+		})
+	}
+
+	return cat
+}
+
+func genColor(r *rand.Rand) reldb.Color {
+	return reldb.Color{
+		IColorID: r.Uint32(),
+		VName:    genText(r),
+		VColor:   genString(r),
+		IRank:    r.Int63n(1000),
+		CStatus:  []string{"A", "I"}[r.Intn(2)],
+	}
+}
+
+func genSKU(r *rand.Rand) reldb.SKU {
+
+	sku := reldb.SKU{
+		VSKUID:  genString(r),
+		IProdID: r.Uint32(),
+		Attributes: genList(r, func(r *rand.Rand) reldb.SKUAttrib {
+			return reldb.SKUAttrib{VAttribName: genString(r), VAttribValue: genString(r)}
+		}),
+		FRetailPrice:  genMoney(r),
+		FRetailOPrice: genMoney(r),
+		FPrice:        genMoney(r),
+		FOPrice:       genMoney(r),
+		CDefault:      []string{"", "Y", "N"}[r.Intn(3)],
+		CStock:        []string{"", "Y", "N"}[r.Intn(3)],
+	}
+	// Only top level strings are compressed, so a nested color keeps a
+	// short name
+	if r.Intn(2) == 0 {
+		color := genColor(r)
+		color.VName = genString(r)
+		sku.Color = &color
+	}
+
+	return sku
+}
+
+func genProductAttribute(r *rand.Rand) reldb.ProductAttribute {
+	return reldb.ProductAttribute{
+		IProdAttribID: r.Uint32(),
+		IProdID:       r.Uint32(),
+		IAttribID:     r.Uint32(),
+		VAttribName:   genStringPtr(r),
+		VValue:        genStringPtr(r),
+		IPCID:         r.Uint32(),
+		FRetailPrice:  genMoney(r),
+		FRetailOPrice: genMoney(r),
+		FPrice:        genMoney(r),
+		FOPrice:       genMoney(r),
+		CDefault:      genStringPtr(r),
+		CStock:        genStringPtr(r),
+	}
+}
+
+func genProduct(r *rand.Rand) reldb.Product {
+
+	// A product without a status is stored as inactive
+	status := []string{"A", "I"}[r.Intn(2)]
+
+	return reldb.Product{
+		IProdID:          r.Uint32(),
+		IPCatID:          r.Uint32(),
+		CCode:            genStringPtr(r),
+		VName:            genString(r),
+		VCategoryName:    genString(r),
+		VURLName:         genString(r),
+		VCategoryURLName: genString(r),
+		VShortDesc:       genTextPtr(r),
+		VDescription:     genTextPtr(r),
+		ProdPrice: reldb.ProdPrice{
+			FRetailPrice:      genMoney(r),
+			FRetailOPrice:     genMoney(r),
+			FShipping:         genMoney(r),
+			FPrice:            genMoney(r),
+			FOPrice:           genMoney(r),
+			FActualWeight:     r.Float64() * 100,
+			FVolumetricWeight: r.NormFloat64(),
+		},
+		Images:     genImages(r),
+		ImagesMeta: genImagesMeta(r),
+		CStatus:    &status,
+		VYTID:      genStringPtr(r),
+		Attributes: genList(r, genProductAttribute),
+		SKUs:       genList(r, genSKU),
+		Path:       genList(r, genCrumb),
+	}
+}
+
+func genAttribute(r *rand.Rand) reldb.Attribute {
+	return reldb.Attribute{
+		IAttribID:      r.Int31(),
+		VName:          genText(r),
+		IRank:          r.Int31n(100),
+		CFilterDisplay: []string{"", "Y", "N"}[r.Intn(3)],
+		CStatus:        []string{"A", "I"}[r.Intn(2)],
+	}
+}
+
+func genOrderProduct(r *rand.Rand) reldb.OrderProduct {
+	return reldb.OrderProduct{
+		IProdID:         r.Uint32(),
+		VURLName:        genStringPtr(r),
+		VName:           genString(r),
+		VCategoryName:   genString(r),
+		VAttributeValue: genStringPtr(r),
+		VColorValue:     genStringPtr(r),
+		IQty:            r.Intn(10),
+		FRate:           genMoney(r),
+		FPayable:        genMoney(r),
+		CGiftWrap:       genStringPtr(r),
+	}
+}
+
+func genOrderShipment(r *rand.Rand) reldb.OrderShipment {
+	return reldb.OrderShipment{
+		IOrdShipID:   r.Uint32(),
+		IOrdID:       r.Uint32(),
+		ICourierID:   r.Uint32(),
+		VCourierName: genStringPtr(r),
+		VLink:        genStringPtr(r),
+		VShipCode:    genStringPtr(r),
+		DtUpdatedAt:  genTime(r),
+	}
+}
+
+func genOrder(r *rand.Rand) reldb.Order {
+
+	order := reldb.Order{
+		IOrdID: r.Uint32(),
+		DtDt:   genTime(r),
+		OrderRecipient: reldb.OrderRecipient{
+			VRecpName:        genStringPtr(r),
+			VRecpEmail:       genStringPtr(r),
+			VRecpAddress:     genStringPtr(r),
+			VRecpAddlAddress: genStringPtr(r),
+			VRecpCity:        genStringPtr(r),
+			VRecpState:       genStringPtr(r),
+			VRecpCountryName: genStringPtr(r),
+			VRecpPincode:     genStringPtr(r),
+			VRecpPhone:       genStringPtr(r),
+			VRecpLandLine:    genStringPtr(r),
+		},
+		OrderBilling: reldb.OrderBilling{
+			VBillName:        genStringPtr(r),
+			VBillEmail:       genStringPtr(r),
+			VBillAddress:     genStringPtr(r),
+			VBillCity:        genStringPtr(r),
+			VBillState:       genStringPtr(r),
+			VBillCountryName: genStringPtr(r),
+			VBillPincode:     genStringPtr(r),
+			VBillPhone:       genStringPtr(r),
+			VBillLandLine:    genStringPtr(r),
+		},
+		OrderPayment: reldb.OrderPayment{
+			FTotalRate:       genMoney(r),
+			FTotalDiscount:   genMoney(r),
+			FShipping:        genMoney(r),
+			FTotal:           genMoney(r),
+			VInstructions:    genTextPtr(r),
+			VRemarks:         genTextPtr(r),
+			CPaymentType:     genStringPtr(r),
+			VBankName:        genStringPtr(r),
+			VChequeNo:        genStringPtr(r),
+			IInvoiceCode:     uint(r.Uint32()),
+			CPaymentStatus:   genStringPtr(r),
+			CStatus:          genStringPtr(r),
+			IDiscCoupID:      r.Intn(1000),
+			FGiftWrapCharges: genMoney(r),
+		},
+		OrderGiftOptions: reldb.OrderGiftOptions{
+			IGWCardID:        r.Uint32(),
+			VGiftCardName:    genStringPtr(r),
+			VGiftWrapMessage: genStringPtr(r),
+		},
+		OrderShipments: genList(r, genOrderShipment),
+		OrderProducts:  genList(r, genOrderProduct),
+	}
+	if r.Intn(2) == 0 {
+		disc := genMoney(r)
+		order.FCouponDisc = &disc
+	}
+
+	return order
+}
+
+func genRecipient(r *rand.Rand) reldb.Recipient {
+	return reldb.Recipient{
+		Set:    genString(r),
+		EMail:  genString(r),
+		Name:   genText(r),
+		Status: []string{"A", "I"}[r.Intn(2)],
+	}
+}
+
+const roundTrips = 100
+
+// checkRoundTrip checks that Decode(Encode(x)) is x for values of gen,
+// under every naming policy, as written and after compressItem. It returns
+// how many items compressItem actually compressed.
+func checkRoundTrip[T any](t *testing.T, codec ItemCodec[T], gen func(*rand.Rand) T) int {
+
+	t.Helper()
+	defer func(naming Naming) { ItemNaming = naming }(ItemNaming)
+
+	compressions := 0
+
+	for _, naming := range []Naming{NamingField, NamingCamel, NamingSource, NamingShort} {
+		ItemNaming = naming
+		r := rand.New(rand.NewSource(int64(len(naming))))
+		for i := 0; i < roundTrips; i++ {
+
+			want := gen(r)
+			item, err := codec.Encode(want)
+			if err != nil {
+				t.Fatalf("%s: error encoding %+v: %s", naming, want, err)
+			}
+			compressed, err := compressItem(item)
+			if err != nil {
+				t.Fatalf("%s: error compressing %+v: %s", naming, want, err)
+			}
+			if _, ok := compressed[CompressedAttribute]; ok {
+				compressions++
+			}
+
+			for stage, item := range map[string]map[string]types.AttributeValue{"encoded": item, "compressed": compressed} {
+				got, err := codec.Decode(item)
+				if err != nil {
+					t.Fatalf("%s, %s: error decoding %+v: %s", naming, stage, want, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("%s, %s: round trip changed the value\n got: %+v\nwant: %+v", naming, stage, got, want)
+				}
+			}
+		}
+	}
+
+	return compressions
+}
+
+func TestCategoryRoundTrip(t *testing.T) {
+	if checkRoundTrip(t, CategoryCodec{}, func(r *rand.Rand) reldb.CategorySummary { return genCategory(r, 0) }) == 0 {
+		t.Error("no category was large enough to be compressed")
+	}
+}
+
+func TestProductRoundTrip(t *testing.T) {
+	if checkRoundTrip(t, ProductCodec{}, genProduct) == 0 {
+		t.Error("no product was large enough to be compressed")
+	}
+}
+
+// SKU items have no text outside their key, so they are never compressed.
+func TestSKURoundTrip(t *testing.T) {
+	checkRoundTrip(t, SKUCodec{}, genSKU)
+}
+
+func TestColorRoundTrip(t *testing.T) {
+	if checkRoundTrip(t, ColorCodec{}, genColor) == 0 {
+		t.Error("no color was large enough to be compressed")
+	}
+}
+
+func TestAttributeRoundTrip(t *testing.T) {
+	if checkRoundTrip(t, AttributeCodec{}, genAttribute) == 0 {
+		t.Error("no attribute was large enough to be compressed")
+	}
+}
+
+func TestOrderRoundTrip(t *testing.T) {
+	if checkRoundTrip(t, OrderCodec{}, genOrder) == 0 {
+		t.Error("no order was large enough to be compressed")
+	}
+}
+
+func TestRecipientRoundTrip(t *testing.T) {
+	if checkRoundTrip(t, RecipientCodec{}, genRecipient) == 0 {
+		t.Error("no recipient was large enough to be compressed")
+	}
+}
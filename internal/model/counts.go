@@ -0,0 +1,91 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CountsResult is what AddProductCounts did. Unapplied holds the deltas
+// that were not added, including those of Missing, so they can be fed
+// again without counting any change twice.
+type CountsResult struct {
+	Updated   int
+	Missing   []uint32
+	Unapplied []reldb.CountDelta
+}
+
+// AddProductCounts adds deltas, as made by reldb.CountDeltas, to the
+// product counts of the category items, one UpdateItem ADD per category,
+// so concurrent adjustments never overwrite each other. A category without
+// an item is recorded as missing and the rest are still updated. Any other
+// error stops the adjustment. Counts in the children lists of parent items
+// are not updated.
+func (basics TableBasics) AddProductCounts(ctx context.Context, deltas []reldb.CountDelta) (CountsResult, error) {
+
+	result := CountsResult{}
+	for i, delta := range deltas {
+
+		if err := basics.Limiter.Wait(ctx); err != nil {
+			result.Unapplied = append(result.Unapplied, deltas[i:]...)
+			return result, context.Cause(ctx)
+		}
+
+		_, err := basics.DynamoDbClient.UpdateItem(ctx, basics.countUpdate(delta))
+		var conditionFailed *types.ConditionalCheckFailedException
+		switch {
+		case errors.As(err, &conditionFailed):
+			result.Missing = append(result.Missing, delta.IPCatID)
+			result.Unapplied = append(result.Unapplied, delta)
+		case err != nil:
+			result.Unapplied = append(result.Unapplied, deltas[i:]...)
+			return result, fmt.Errorf("error adjusting counts of category %d: %s", delta.IPCatID, err)
+		default:
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// countUpdate is the ADD of delta to the item of its category. The counts
+// are named by ItemNaming like every other attribute.
+func (basics TableBasics) countUpdate(delta reldb.CountDelta) *dynamodb.UpdateItemInput {
+
+	pk, sk := CategoryKey(reldb.CategorySummary{IPCatID: delta.IPCatID})
+	names := map[string]string{}
+	values := map[string]types.AttributeValue{}
+	adds := []string{}
+	for _, count := range []struct {
+		field string
+		by    int
+	}{{"IProductCount", delta.IProductCount}, {"ISubtreeCount", delta.ISubtreeCount}} {
+		field, by := count.field, count.by
+		if by == 0 {
+			continue
+		}
+		names["#"+field] = attributeNameIndex[field].Name(ItemNaming)
+		values[":"+field] = &types.AttributeValueMemberN{Value: strconv.Itoa(by)}
+		adds = append(adds, fmt.Sprintf("#%s :%s", field, field))
+	}
+
+	return &dynamodb.UpdateItemInput{
+		TableName: aws.String(basics.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: pk},
+			"SK": &types.AttributeValueMemberS{Value: sk},
+		},
+		UpdateExpression:          aws.String("ADD " + strings.Join(adds, ", ")),
+		ConditionExpression:       aws.String("attribute_exists(PK)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+}
@@ -0,0 +1,323 @@
+package model
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DeadLetter is an item that could not be written. Item is nil when the
+// failure happened before the item existed, e.g. when marshaling it.
+type DeadLetter struct {
+	Entity   string                          `json:"entity"`
+	PK       string                          `json:"pk"`
+	SK       string                          `json:"sk"`
+	Error    string                          `json:"error"`
+	Attempts int                             `json:"attempts"`
+	FailedAt time.Time                       `json:"failedAt"`
+	Item     map[string]types.AttributeValue `json:"-"`
+}
+
+// NewDeadLetter describes an item that failed with err after attempts tries.
+func NewDeadLetter(entity string, item map[string]types.AttributeValue, err error, attempts int) DeadLetter {
+
+	pk, sk := itemKey(item)
+	return DeadLetter{
+		Entity:   entity,
+		PK:       pk,
+		SK:       sk,
+		Error:    err.Error(),
+		Attempts: attempts,
+		FailedAt: time.Now(),
+		Item:     item,
+	}
+}
+
+// itemKey returns the string PK and SK of an item, empty if missing.
+func itemKey(item map[string]types.AttributeValue) (string, string) {
+
+	var pk, sk string
+	if v, ok := item["PK"].(*types.AttributeValueMemberS); ok {
+		pk = v.Value
+	}
+	if v, ok := item["SK"].(*types.AttributeValueMemberS); ok {
+		sk = v.Value
+	}
+
+	return pk, sk
+}
+
+// RejectedLetter describes a source row with the given key that could not
+// be turned into an item at all.
+func RejectedLetter(entity, pk, sk string, err error) DeadLetter {
+
+	return DeadLetter{
+		Entity:   entity,
+		PK:       pk,
+		SK:       sk,
+		Error:    err.Error(),
+		Attempts: 1,
+		FailedAt: time.Now(),
+	}
+}
+
+// MarshalJSON stores the item in the DynamoDB JSON format so that it decodes
+// back to exactly the same item.
+func (d DeadLetter) MarshalJSON() ([]byte, error) {
+
+	type plain DeadLetter
+	out := struct {
+		plain
+		Item map[string]avJSON `json:"item,omitempty"`
+	}{plain: plain(d)}
+
+	if d.Item != nil {
+		out.Item = make(map[string]avJSON, len(d.Item))
+		for name, av := range d.Item {
+			out.Item[name] = toAVJSON(av)
+		}
+	}
+
+	return json.Marshal(out)
+}
+
+func (d *DeadLetter) UnmarshalJSON(data []byte) error {
+
+	type plain DeadLetter
+	in := struct {
+		plain
+		Item map[string]avJSON `json:"item,omitempty"`
+	}{}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*d = DeadLetter(in.plain)
+	if in.Item != nil {
+		d.Item = make(map[string]types.AttributeValue, len(in.Item))
+		for name, av := range in.Item {
+			value, err := av.attributeValue()
+			if err != nil {
+				return fmt.Errorf("error decoding attribute %s: %w", name, err)
+			}
+			d.Item[name] = value
+		}
+	}
+
+	return nil
+}
+
+// DeadLetters appends dead letters to a JSON-lines file, one letter per
+// line. The file is only created when the first letter arrives. A nil
+// *DeadLetters only logs the letters.
+type DeadLetters struct {
+	mu       sync.Mutex
+	fileName string
+	file     *os.File
+	count    int
+}
+
+func NewDeadLetters(fileName string) *DeadLetters {
+	return &DeadLetters{fileName: fileName}
+}
+
+// Add records a dead letter.
+func (d *DeadLetters) Add(letter DeadLetter) error {
+
+	log.Printf("Dead letter %s %s/%s after %d attempts: %s\n", letter.Entity, letter.PK, letter.SK, letter.Attempts, letter.Error)
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.file == nil {
+		file, err := os.OpenFile(d.fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("error opening dead letter file %s: %w", d.fileName, err)
+		}
+		d.file = file
+	}
+
+	jsonBytes, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("error marshaling dead letter: %w", err)
+	}
+	if _, err := d.file.Write(append(jsonBytes, '\n')); err != nil {
+		return fmt.Errorf("error writing dead letter: %w", err)
+	}
+	d.count++
+
+	return nil
+}
+
+// Count is the number of letters added so far.
+func (d *DeadLetters) Count() int {
+
+	if d == nil {
+		return 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.count
+}
+
+// FileName is the file the letters are written to.
+func (d *DeadLetters) FileName() string {
+
+	if d == nil {
+		return ""
+	}
+
+	return d.fileName
+}
+
+func (d *DeadLetters) Close() error {
+
+	if d == nil || d.file == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	err := d.file.Close()
+	d.file = nil
+
+	return err
+}
+
+// ReadDeadLetters reads back a file written by DeadLetters.
+func ReadDeadLetters(fileName string) ([]DeadLetter, error) {
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error opening dead letter file %s: %w", fileName, err)
+	}
+	defer file.Close()
+
+	letters := []DeadLetter{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024) // items go up to 400KB
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		letter := DeadLetter{}
+		if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+			return nil, fmt.Errorf("error decoding line %d of %s: %w", line, fileName, err)
+		}
+		letters = append(letters, letter)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", fileName, err)
+	}
+
+	return letters, nil
+}
+
+// avJSON is an attribute value in the DynamoDB JSON format, e.g.
+// {"S": "abc"} or {"M": {"n": {"N": "1"}}}.
+type avJSON struct {
+	S    *string            `json:"S,omitempty"`
+	N    *string            `json:"N,omitempty"`
+	B    []byte             `json:"B,omitempty"`
+	BOOL *bool              `json:"BOOL,omitempty"`
+	NULL *bool              `json:"NULL,omitempty"`
+	SS   []string           `json:"SS,omitempty"`
+	NS   []string           `json:"NS,omitempty"`
+	BS   [][]byte           `json:"BS,omitempty"`
+	L    *[]avJSON          `json:"L,omitempty"`
+	M    *map[string]avJSON `json:"M,omitempty"`
+}
+
+func toAVJSON(av types.AttributeValue) avJSON {
+
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return avJSON{S: &v.Value}
+	case *types.AttributeValueMemberN:
+		return avJSON{N: &v.Value}
+	case *types.AttributeValueMemberB:
+		return avJSON{B: v.Value}
+	case *types.AttributeValueMemberBOOL:
+		return avJSON{BOOL: &v.Value}
+	case *types.AttributeValueMemberNULL:
+		return avJSON{NULL: &v.Value}
+	case *types.AttributeValueMemberSS:
+		return avJSON{SS: v.Value}
+	case *types.AttributeValueMemberNS:
+		return avJSON{NS: v.Value}
+	case *types.AttributeValueMemberBS:
+		return avJSON{BS: v.Value}
+	case *types.AttributeValueMemberL:
+		list := make([]avJSON, len(v.Value))
+		for i, elem := range v.Value {
+			list[i] = toAVJSON(elem)
+		}
+		return avJSON{L: &list}
+	case *types.AttributeValueMemberM:
+		m := make(map[string]avJSON, len(v.Value))
+		for name, elem := range v.Value {
+			m[name] = toAVJSON(elem)
+		}
+		return avJSON{M: &m}
+	}
+
+	return avJSON{}
+}
+
+func (a avJSON) attributeValue() (types.AttributeValue, error) {
+
+	switch {
+	case a.S != nil:
+		return &types.AttributeValueMemberS{Value: *a.S}, nil
+	case a.N != nil:
+		return &types.AttributeValueMemberN{Value: *a.N}, nil
+	case a.B != nil:
+		return &types.AttributeValueMemberB{Value: a.B}, nil
+	case a.BOOL != nil:
+		return &types.AttributeValueMemberBOOL{Value: *a.BOOL}, nil
+	case a.NULL != nil:
+		return &types.AttributeValueMemberNULL{Value: *a.NULL}, nil
+	case a.SS != nil:
+		return &types.AttributeValueMemberSS{Value: a.SS}, nil
+	case a.NS != nil:
+		return &types.AttributeValueMemberNS{Value: a.NS}, nil
+	case a.BS != nil:
+		return &types.AttributeValueMemberBS{Value: a.BS}, nil
+	case a.L != nil:
+		list := make([]types.AttributeValue, len(*a.L))
+		for i, elem := range *a.L {
+			av, err := elem.attributeValue()
+			if err != nil {
+				return nil, err
+			}
+			list[i] = av
+		}
+		return &types.AttributeValueMemberL{Value: list}, nil
+	case a.M != nil:
+		m := make(map[string]types.AttributeValue, len(*a.M))
+		for name, elem := range *a.M {
+			av, err := elem.attributeValue()
+			if err != nil {
+				return nil, err
+			}
+			m[name] = av
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	}
+
+	return nil, fmt.Errorf("attribute value has no type")
+}
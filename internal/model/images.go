@@ -0,0 +1,48 @@
+package model
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// SetImage replaces field, e.g. VImage, of the images map of the item
+// pk/sk with path, or removes it when path is nil, which reads back as
+// NULL. Items that do not exist are not created. Copies of a category in
+// the children lists of its parents are not changed.
+func (basics TableBasics) SetImage(ctx context.Context, pk, sk, field string, path *string) error {
+
+	a, known := attributeNameIndex[field]
+	if !known {
+		return fmt.Errorf("%s is not an image field", field)
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(basics.TableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: pk},
+			"SK": &types.AttributeValueMemberS{Value: sk},
+		},
+		UpdateExpression:    aws.String("REMOVE #images.#field"),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		ExpressionAttributeNames: map[string]string{
+			"#images": attributeNameIndex["MImages"].Name(ItemNaming),
+			"#field":  a.Name(ItemNaming),
+		},
+	}
+	if path != nil {
+		input.UpdateExpression = aws.String("SET #images.#field = :path")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":path": &types.AttributeValueMemberS{Value: *path},
+		}
+	}
+
+	if _, err := basics.DynamoDbClient.UpdateItem(ctx, input); err != nil {
+		return fmt.Errorf("error setting %s of %s/%s: %s", field, pk, sk, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// RowItem converts a row returned by reldb.QueryRows into a DynamoDB item.
+// The columns named pkCol and skCol (matched case insensitively) become the
+// PK and SK of the item; every other non NULL column is stored as an
+// attribute of the same name.
+func RowItem(row reldb.Row, pkCol, skCol string) (map[string]types.AttributeValue, error) {
+
+	item := make(map[string]types.AttributeValue, len(row))
+	for column, value := range row {
+
+		name := column
+		switch {
+		case strings.EqualFold(column, pkCol):
+			name = "PK"
+		case strings.EqualFold(column, skCol):
+			name = "SK"
+		}
+
+		if value == nil {
+			continue
+		}
+
+		av, err := rowValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("error converting column %s: %w", column, err)
+		}
+		item[name] = av
+	}
+
+	for _, key := range []string{"PK", "SK"} {
+		keyVal, exists := item[key]
+		if !exists {
+			return nil, fmt.Errorf("row has no %s column", key)
+		}
+		if _, isString := keyVal.(*types.AttributeValueMemberS); !isString {
+			return nil, fmt.Errorf("%s column must be a string", key)
+		}
+	}
+
+	return item, nil
+}
+
+func rowValue(value any) (types.AttributeValue, error) {
+
+	switch v := value.(type) {
+	case string:
+		return &types.AttributeValueMemberS{Value: v}, nil
+	case json.Number:
+		return &types.AttributeValueMemberN{Value: v.String()}, nil
+	case time.Time:
+		return &types.AttributeValueMemberS{Value: v.Format(time.RFC3339)}, nil
+	case []byte:
+		return &types.AttributeValueMemberB{Value: v}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value of type %T", value)
+}
+
+// BatchResult counts what happened to the items handed to AddItemBatch.
+// Remaining are the items that were never sent, either because the
+// maximum was reached or because the write was interrupted.
+type BatchResult struct {
+	Written   int `json:"written"`
+	Failed    int `json:"failed"`
+	Remaining int `json:"remaining"`
+}
+
+// maxAttempts is how often a batch, or the part of it DynamoDB left
+// unprocessed, is sent before its items are given up as dead letters.
+const maxAttempts = 4
+
+// AddItemBatch adds a slice of ready made items of one entity to the
+// DynamoDB table. The function sends batches of 25 items to DynamoDB until
+// all items are added or it reaches the specified maximum. Batches wait on
+// the table's rate limiter so concurrent migrations share the write budget.
+// Failed batches and unprocessed items are retried with backoff and, when
+// they still fail, handed to the table's dead letters to be replayed. They
+// are counted in Failed and do not make AddItemBatch fail.
+//
+// Once ctx is done no new batch is started, but a batch already sent is
+// allowed to complete so its outcome is known. The returned error is then
+// the cause of the cancellation. The other errors are those that lose
+// items: a dead letter that cannot be recorded, after which no further
+// batch is sent, or failed items without dead letters to record them in.
+func (basics TableBasics) AddItemBatch(ctx context.Context, entity string, items []map[string]types.AttributeValue, maxItems int) (BatchResult, error) {
+
+	result := BatchResult{}
+	batchSize := 25 // DynamoDB allows a maximum batch size of 25 items.
+	start := 0
+	end := start + batchSize
+	for start < maxItems && start < len(items) {
+
+		if end > len(items) {
+			end = len(items)
+		}
+		if end > maxItems {
+			end = maxItems
+		}
+
+		if ctx.Err() != nil || basics.Limiter.Wait(ctx) != nil {
+			result.Remaining = len(items) - start
+			return result, context.Cause(ctx)
+		}
+
+		var writeReqs []types.WriteRequest
+		for _, item := range items[start:end] {
+			writeReqs = append(
+				writeReqs,
+				types.WriteRequest{PutRequest: &types.PutRequest{Item: item}},
+			)
+		}
+
+		// Do not abandon a batch halfway on interrupt, drain it instead
+		failed, err := basics.writeBatch(context.WithoutCancel(ctx), entity, writeReqs)
+		result.Written += len(writeReqs) - failed
+		result.Failed += failed
+
+		start = end
+		end += batchSize
+
+		if err != nil {
+			result.Remaining = len(items) - start
+			return result, err
+		}
+	}
+	result.Remaining = len(items) - start
+
+	if result.Failed > 0 && basics.DeadLetters == nil {
+		return result, fmt.Errorf("%d %s items could not be written and there is no dead letter file to replay them from", result.Failed, entity)
+	}
+
+	return result, nil
+}
+
+// writeBatch sends one batch, retrying what fails, and returns the number
+// of items that could not be written and were made dead letters. The error
+// is that of recording a dead letter.
+func (basics TableBasics) writeBatch(ctx context.Context, entity string, writeReqs []types.WriteRequest) (int, error) {
+
+	var err error
+
+	pending := writeReqs
+	attempt := 0
+	for len(pending) > 0 && attempt < maxAttempts {
+
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<attempt) * 100 * time.Millisecond)
+		}
+		attempt++
+
+		requests := map[string][]types.WriteRequest{basics.TableName: pending}
+		var output *dynamodb.BatchWriteItemOutput
+		output, err = basics.DynamoDbClient.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: requests,
+		})
+		if err != nil {
+			log.Printf("Couldn't add a batch of %s to %v (attempt %d). Here's why: %v\n", entity, basics.TableName, attempt, err)
+			continue
+		}
+		pending = output.UnprocessedItems[basics.TableName]
+	}
+
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	reason := err
+	if reason == nil {
+		reason = fmt.Errorf("left unprocessed by %s", basics.TableName)
+	}
+	for _, req := range pending {
+		letter := NewDeadLetter(entity, req.PutRequest.Item, reason, attempt)
+		if err := basics.DeadLetters.Add(letter); err != nil {
+			return len(pending), err
+		}
+	}
+
+	return len(pending), nil
+}
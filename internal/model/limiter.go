@@ -0,0 +1,47 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter spaces out batch writes so that every batch, no matter which
+// migration sends it, waits at least interval after the previous one.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+func NewRateLimiter(interval time.Duration) *RateLimiter {
+	return &RateLimiter{interval: interval}
+}
+
+// Wait blocks until the next batch may be sent or ctx is done.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.last.IsZero() {
+		if wait := time.Until(r.last.Add(r.interval)); wait > 0 {
+			fmt.Printf("Sleeping for %s before the next batch\n", wait.Round(time.Second))
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+	r.last = time.Now()
+
+	return nil
+}
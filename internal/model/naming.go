@@ -0,0 +1,198 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Naming is the policy that turns the Go field names of the item structs
+// into stored attribute names.
+type Naming string
+
+const (
+	// NamingField keeps the Go field names, e.g. VCategoryName.
+	NamingField Naming = "field"
+	// NamingCamel uses camelCase names, e.g. categoryName.
+	NamingCamel Naming = "camel"
+	// NamingSource uses the mysql column names, e.g. vCategoryName.
+	NamingSource Naming = "source"
+	// NamingShort uses short codes, e.g. cn, for the smallest items.
+	NamingShort Naming = "short"
+)
+
+// ItemNaming is the policy used by the item codecs to encode and decode
+// items. Commands set it once before building items.
+var ItemNaming = NamingCamel
+
+// ParseNaming checks a naming policy given on the command line.
+func ParseNaming(s string) (Naming, error) {
+
+	switch naming := Naming(s); naming {
+	case NamingField, NamingCamel, NamingSource, NamingShort:
+		return naming, nil
+	}
+
+	return "", fmt.Errorf("unknown naming %q (want field, camel, source or short)", s)
+}
+
+// AttributeName maps a Go field of the item structs to its stored name
+// under every naming policy. The same field name gets the same attribute
+// name wherever it is nested.
+type AttributeName struct {
+	Field       string `json:"field"`
+	Source      string `json:"source"`
+	Camel       string `json:"camel"`
+	Short       string `json:"short"`
+	Description string `json:"description"`
+}
+
+// Name is the attribute name under naming.
+func (a AttributeName) Name(naming Naming) string {
+
+	switch naming {
+	case NamingCamel:
+		return a.Camel
+	case NamingSource:
+		return a.Source
+	case NamingShort:
+		return a.Short
+	}
+
+	return a.Field
+}
+
+// AttributeNames is the naming table. PK and SK are never renamed.
+var AttributeNames = []AttributeName{
+	{"IPCatID", "iPCatID", "categoryId", "ci", "category id"},
+	{"IParentID", "iParentID", "parentId", "pi", "parent category id, 0 for the root"},
+	{"IProdID", "iProdID", "productId", "pd", "product id"},
+	{"VSKUID", "vSKUID", "skuId", "id", "sku id, from the product, color and attribute ids"},
+	{"IAttribID", "iAttribID", "attributeId", "ai", "attribute id"},
+	{"IAttribDatID", "iAttribDatID", "categoryAttributeId", "ca", "category attribute value id"},
+	{"IProdAttribID", "iProdAttribID", "productAttributeId", "pa", "product attribute value id"},
+	{"IPCID", "iAttribPCID", "productColorId", "pc", "product color an attribute belongs to"},
+	{"IRank", "iRank", "rank", "rk", "display order"},
+	{"IProductCount", "iProductCount", "productCount", "np", "number of products in the category"},
+	{"VName", "vName", "name", "n", "name, or value of a category attribute"},
+	{"VURLName", "vUrlName", "urlName", "u", "name used in urls"},
+	{"VCategoryName", "vCategoryName", "categoryName", "cn", "category name"},
+	{"VCategoryURLName", "vCategoryUrlName", "categoryUrlName", "cu", "category name used in urls"},
+	{"VShortDesc", "vShortDesc", "shortDescription", "sd", "short description"},
+	{"VShortDescription", "vShortDesc", "shortDescription", "sd", "short description"},
+	{"VDescription", "vDescription", "description", "d", "description"},
+	{"VAttribName", "vAttribName", "attributeName", "an", "attribute name"},
+	{"VAttribValue", "vValue", "attributeValue", "av", "attribute value of a sku"},
+	{"VValue", "vValue", "value", "v", "attribute value of a product"},
+	{"VYTID", "vYTID", "youtubeId", "yt", "youtube video id"},
+	{"CCode", "cCode", "code", "cc", "product code"},
+	{"CStatus", "cStatus", "status", "st", "A active, I inactive"},
+	{"CTypeStatus", "cTypeStatus", "typeStatus", "ts", "item type (C, P) followed by status"},
+	{"CDefault", "cDefault", "default", "df", "Y for the default choice"},
+	{"CStock", "cStock", "inStock", "is", "Y when in stock"},
+	{"Color", "color", "color", "co", "color of a sku"},
+	{"IColorID", "iColorID", "colorId", "cl", "color id"},
+	{"VColor", "vColor", "swatch", "sw", "hex swatch of a color"},
+	{"MImages", "images", "images", "im", "map of images"},
+	{"VSmallImage", "vSmallImage", "smallImage", "si", "thumbnail path"},
+	{"VSmallImageAltTag", "vSmallImage_AltTag", "smallImageAlt", "sa", "thumbnail alt text"},
+	{"VImage", "vImage", "image", "i", "image path"},
+	{"VImageAltTag", "vImage_AltTag", "imageAlt", "ia", "image alt text"},
+	{"MImageMeta", "imageMeta", "imageMeta", "mm", "map of image file metadata, keyed like images"},
+	{"IWidth", "iWidth", "width", "wd", "image width in pixels"},
+	{"IHeight", "iHeight", "height", "ht", "image height in pixels"},
+	{"VFormat", "vFormat", "format", "fm", "image format, e.g. jpeg"},
+	{"IBytes", "iBytes", "bytes", "by", "image file size"},
+	{"VSHA256", "vSHA256", "sha256", "ha", "sha256 of the image file"},
+	{"MPrices", "prices", "prices", "pr", "map of prices and weights"},
+	{"FRetailPrice", "fRetailPrice", "retailPrice", "rp", "retail price"},
+	{"FRetailOPrice", "fRetailOPrice", "retailOriginalPrice", "ro", "retail price before discount"},
+	{"FPrice", "fPrice", "price", "p", "price"},
+	{"FOPrice", "fOPrice", "originalPrice", "op", "price before discount"},
+	{"FShipping", "fShipping", "shipping", "sh", "shipping charge"},
+	{"FActualWeight", "fActualWeight", "actualWeight", "aw", "weight"},
+	{"FVolumetricWeight", "fVolumetricWeight", "volumetricWeight", "vw", "volumetric weight"},
+	{"LAttributes", "attributes", "attributes", "at", "list of attributes"},
+	{"Attributes", "attributes", "attributes", "at", "list of attributes"},
+	{"LFacets", "facets", "facets", "fa", "iAttribIDs of the filters a category page shows"},
+	{"Facets", "facets", "facets", "fa", "iAttribIDs of the filters a category page shows"},
+	{"LFacetCounts", "facetCounts", "facetCounts", "fc", "products per filter value"},
+	{"ICount", "iCount", "count", "c", "active products of the category"},
+	{"ISubtreeCount", "iSubtreeCount", "subtreeCount", "sc", "active products of the category and its descendants"},
+	{"LPriceBuckets", "priceBuckets", "priceBuckets", "pb", "products per price range"},
+	{"FFrom", "fFrom", "from", "fr", "lowest price of the bucket"},
+	{"FTo", "fTo", "to", "to", "price the bucket ends before, none for the last"},
+	{"CFilterDisplay", "cFilterDisplay", "filterDisplay", "fd", "how the filter is shown, N for not at all"},
+	{"BFilter", "bFilter", "isFilter", "if", "true when the attribute is a filter facet"},
+	{"LPath", "path", "path", "bc", "breadcrumb from the top level category down"},
+	{"LChildren", "children", "children", "ch", "list of child categories"},
+	{"Children", "children", "children", "ch", "list of child categories"},
+	{"LSKUs", "skus", "skus", "sku", "list of skus"},
+	{"Set", "sset", "set", "se", "recipient set"},
+	{"EMail", "email", "email", "e", "recipient email"},
+	{"Name", "name", "name", "n", "recipient name"},
+	{"Status", "status", "status", "st", "recipient status"},
+}
+
+var attributeNameIndex = func() map[string]AttributeName {
+
+	index := make(map[string]AttributeName, len(AttributeNames))
+	for _, a := range AttributeNames {
+		index[a.Field] = a
+	}
+
+	return index
+}()
+
+// nameItem renames the attributes of an item marshaled from the item
+// structs according to ItemNaming and drops nil and empty values at any
+// depth, so they take no space. Zero numbers and false are kept.
+func nameItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+
+	named := make(map[string]types.AttributeValue, len(item))
+	for field, av := range item {
+		av = nameValue(av)
+		if isEmpty(av) {
+			continue
+		}
+		name := field
+		if a, ok := attributeNameIndex[field]; ok {
+			name = a.Name(ItemNaming)
+		}
+		named[name] = av
+	}
+
+	return named
+}
+
+func nameValue(av types.AttributeValue) types.AttributeValue {
+
+	switch v := av.(type) {
+	case *types.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: nameItem(v.Value)}
+	case *types.AttributeValueMemberL:
+		list := make([]types.AttributeValue, len(v.Value))
+		for i, elem := range v.Value {
+			list[i] = nameValue(elem)
+		}
+		return &types.AttributeValueMemberL{Value: list}
+	}
+
+	return av
+}
+
+func isEmpty(av types.AttributeValue) bool {
+
+	switch v := av.(type) {
+	case *types.AttributeValueMemberNULL:
+		return true
+	case *types.AttributeValueMemberS:
+		return v.Value == ""
+	case *types.AttributeValueMemberL:
+		return len(v.Value) == 0
+	case *types.AttributeValueMemberM:
+		return len(v.Value) == 0
+	}
+
+	return false
+}
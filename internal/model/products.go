@@ -15,6 +15,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+// CategoryValue and ProductValue are marshaled with their Go field names,
+// which nameItem then turns into the attribute names of ItemNaming.
 type CategoryValue struct {
 	PK                string
 	SK                string
@@ -24,9 +26,14 @@ type CategoryValue struct {
 	IParentID         uint32
 	VShortDescription *string
 	MImages           reldb.Images
+	MImageMeta        *reldb.ImagesMeta
 	CTypeStatus       string
+	IRank             int
 	IProductCount     int
+	ISubtreeCount     int
 	LAttributes       []reldb.CategoryAttribute
+	LFacets           []uint32
+	LPath             []reldb.Crumb
 	LChildren         []*reldb.CategorySummary
 }
 
@@ -44,10 +51,12 @@ type ProductValue struct {
 	VDescription      *string
 	MPrices           reldb.ProdPrice
 	MImages           reldb.Images
+	MImageMeta        *reldb.ImagesMeta
 	CTypeStatus       string
 	VYTID             *string
 	LAttributes       []reldb.ProductAttribute
 	LSKUs             []reldb.SKU
+	LPath             []reldb.Crumb
 }
 
 // TableBasics encapsulates the Amazon DynamoDB service actions used in the examples.
@@ -55,207 +64,273 @@ type ProductValue struct {
 type TableBasics struct {
 	DynamoDbClient *dynamodb.Client
 	TableName      string
+	Limiter        *RateLimiter
+	DeadLetters    *DeadLetters
 }
 
 var tableName = "MarioGallery"
 var sleepBetweenBatches time.Duration = 30 * time.Second
 
-func PutCategory(ctx context.Context, cat reldb.CategorySummary) error {
+// NewTableBasics returns a TableBasics for the gallery table using the
+// default AWS configuration. All batch writes through the returned value
+// share one rate limiter.
+func NewTableBasics() (*TableBasics, error) {
 
 	cfg, err := config.Configuration()
 	if err != nil {
-		log.Fatalf("error fetching default configuration: %s", err)
+		return nil, fmt.Errorf("error fetching default configuration: %s", err)
 	}
 
-	client := dynamodb.NewFromConfig(cfg)
-
 	// client := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
-	// 	o.BaseEndpoint = aws.String("https://localhost:4000")
+	// 	o.BaseEndpoint = aws.String("http://localhost:4000")
 	// })
 
-	catVal := CategoryValue{
-		PK:                "Category",
-		SK:                fmt.Sprintf("CAT#%d", cat.IPCatID),
+	return &TableBasics{
+		DynamoDbClient: dynamodb.NewFromConfig(cfg),
+		TableName:      tableName,
+		Limiter:        NewRateLimiter(sleepBetweenBatches),
+	}, nil
+}
+
+// CategoryKey returns the PK and SK of the item of a category.
+func CategoryKey(cat reldb.CategorySummary) (string, string) {
+	return "Category", fmt.Sprintf("CAT#%d", cat.IPCatID)
+}
+
+// ProductKey returns the PK and SK of the item of a product.
+func ProductKey(product reldb.Product) (string, string) {
+	return fmt.Sprintf("%sPROD%d", reldb.StringOr(product.CStatus, "I"), product.IProdID), fmt.Sprintf("CAT#%d", product.IPCatID)
+}
+
+// SKUKey returns the PK and SK of the item of a SKU, so that a SKU can be
+// fetched by its ID alone.
+func SKUKey(sku reldb.SKU) (string, string) {
+	return fmt.Sprintf("SKU#%s", sku.VSKUID), fmt.Sprintf("PROD#%d", sku.IProdID)
+}
+
+// ColorKey returns the PK and SK of the item of a color of the color
+// master.
+func ColorKey(color reldb.Color) (string, string) {
+	return "Color", fmt.Sprintf("COL#%d", color.IColorID)
+}
+
+// FacetKey returns the PK and SK of the facet item of a category, a
+// sibling of the category item.
+func FacetKey(cat reldb.CategorySummary) (string, string) {
+	return "Category", fmt.Sprintf("FACET#%d", cat.IPCatID)
+}
+
+// AttributeKey returns the PK and SK of the item of an attribute of the
+// attribute master. The LFacets of a category item are the iAttribIDs of
+// these items.
+func AttributeKey(attrib reldb.Attribute) (string, string) {
+	return "Attribute", fmt.Sprintf("ATTR#%d", attrib.IAttribID)
+}
+
+// RecipientKey returns the PK and SK of the item of a mailing list
+// recipient.
+func RecipientKey(rcpt reldb.Recipient) (string, string) {
+	return fmt.Sprintf("#SET:%s", rcpt.Set), fmt.Sprintf("#A:%s", rcpt.EMail)
+}
+
+func categoryValue(cat reldb.CategorySummary) CategoryValue {
+
+	pk, sk := CategoryKey(cat)
+	return CategoryValue{
+		PK:                pk,
+		SK:                sk,
 		IPCatID:           cat.IPCatID,
 		VCategoryName:     cat.VName,
 		VCategoryURLName:  cat.VURLName,
 		IParentID:         cat.IParentID,
 		VShortDescription: cat.VShortDesc,
 		MImages:           cat.Images,
+		MImageMeta:        cat.ImagesMeta,
 		CTypeStatus:       fmt.Sprintf("C%s", cat.CStatus),
+		IRank:             cat.IRank,
 		IProductCount:     cat.IProductCount,
+		ISubtreeCount:     cat.ISubtreeCount,
 		LAttributes:       cat.Attributes,
+		LFacets:           cat.Facets,
+		LPath:             cat.Path,
 		LChildren:         cat.Children,
 	}
+}
+
+// CategoryItem converts a category into the item stored in the table.
+func CategoryItem(cat reldb.CategorySummary) (map[string]types.AttributeValue, error) {
 
-	av, err := attributevalue.MarshalMap(catVal)
+	item, err := attributevalue.MarshalMap(categoryValue(cat))
 	if err != nil {
-		return fmt.Errorf("error converting category to attribute-value: %s", err)
+		return nil, fmt.Errorf("error converting category %d to attribute-value: %s", cat.IPCatID, err)
 	}
 
-	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(tableName),
-		Item:      av,
+	return nameItem(item), nil
+}
+
+// ProductItem converts a product, with its attributes and SKUs, into the
+// item stored in the table.
+func ProductItem(product reldb.Product) (map[string]types.AttributeValue, error) {
+
+	pk, sk := ProductKey(product)
+	prodVal := ProductValue{
+		PK:                pk,
+		SK:                sk,
+		IProdID:           product.IProdID,
+		IPCatID:           product.IPCatID,
+		VName:             product.VName,
+		VURLName:          product.VURLName,
+		VCategoryName:     product.VCategoryName,
+		VCategoryURLName:  product.VCategoryURLName,
+		CCode:             product.CCode,
+		VShortDescription: product.VShortDesc,
+		VDescription:      product.VDescription,
+		MImages:           product.Images,
+		MImageMeta:        product.ImagesMeta,
+		MPrices:           product.ProdPrice,
+		CTypeStatus:       fmt.Sprintf("P%s", reldb.StringOr(product.CStatus, "I")),
+		LAttributes:       product.Attributes,
+		LSKUs:             product.SKUs,
+		LPath:             product.Path,
+		VYTID:             product.VYTID,
+	}
+
+	item, err := attributevalue.MarshalMap(prodVal)
+	if err != nil {
+		return nil, fmt.Errorf("error converting product %d to attribute-value: %s", product.IProdID, err)
+	}
+
+	return nameItem(item), nil
+}
+
+// SKUValue is the item of a SKU. The same SKU is also stored in the LSKUs
+// list of its product item.
+type SKUValue struct {
+	PK string
+	SK string
+	reldb.SKU
+}
+
+// SKUItem converts a SKU into its own item.
+func SKUItem(sku reldb.SKU) (map[string]types.AttributeValue, error) {
+
+	pk, sk := SKUKey(sku)
+	item, err := attributevalue.MarshalMap(SKUValue{PK: pk, SK: sk, SKU: sku})
+	if err != nil {
+		return nil, fmt.Errorf("error converting sku %s to attribute-value: %s", sku.VSKUID, err)
+	}
+
+	return nameItem(item), nil
+}
+
+// ColorValue is the item of a color of the color master.
+type ColorValue struct {
+	PK string
+	SK string
+	reldb.Color
+}
+
+// ColorItem converts a color into the item stored in the table.
+func ColorItem(color reldb.Color) (map[string]types.AttributeValue, error) {
+
+	pk, sk := ColorKey(color)
+	item, err := attributevalue.MarshalMap(ColorValue{PK: pk, SK: sk, Color: color})
+	if err != nil {
+		return nil, fmt.Errorf("error converting color %d to attribute-value: %s", color.IColorID, err)
+	}
+
+	return nameItem(item), nil
+}
+
+// FacetValue is the facet item of a category: the facet value counts and
+// price buckets a category page shows. It is kept out of the category item
+// because that is nested into the items of all its ancestors.
+type FacetValue struct {
+	PK            string
+	SK            string
+	IPCatID       uint32
+	LFacetCounts  []reldb.FacetCount
+	LPriceBuckets []reldb.PriceBucket
+}
+
+// FacetItem converts the facet counts of a category into its facet item.
+func FacetItem(cat reldb.CategorySummary) (map[string]types.AttributeValue, error) {
+
+	pk, sk := FacetKey(cat)
+	item, err := attributevalue.MarshalMap(FacetValue{
+		PK:            pk,
+		SK:            sk,
+		IPCatID:       cat.IPCatID,
+		LFacetCounts:  cat.FacetCounts,
+		LPriceBuckets: cat.PriceBuckets,
 	})
 	if err != nil {
-		return fmt.Errorf("error adding category: %s", err)
+		return nil, fmt.Errorf("error converting facets of category %d to attribute-value: %s", cat.IPCatID, err)
 	}
 
-	return nil
+	return nameItem(item), nil
 }
 
-// AddCategoryBatch adds a slice of categories to the DynamoDB table. The function sends
-// batches of 25 categories to DynamoDB until all categories are added or it reaches the
-// specified maximum.
-func AddCategoryBatch(ctx context.Context, categories []reldb.CategorySummary, maxCats int) (int, error) {
+// AttributeDefValue is the item of an attribute of the attribute master,
+// with the filter configuration of the storefront.
+type AttributeDefValue struct {
+	PK string
+	SK string
+	reldb.Attribute
+	BFilter bool
+}
 
-	var err error
-	var item map[string]types.AttributeValue
+// AttributeItem converts an attribute into the item stored in the table.
+func AttributeItem(attrib reldb.Attribute) (map[string]types.AttributeValue, error) {
 
-	cfg, err := config.Configuration()
+	pk, sk := AttributeKey(attrib)
+	item, err := attributevalue.MarshalMap(AttributeDefValue{PK: pk, SK: sk, Attribute: attrib, BFilter: attrib.IsFilter()})
 	if err != nil {
-		return 0, fmt.Errorf("error fetching default configuration: %s", err)
+		return nil, fmt.Errorf("error converting attribute %d to attribute-value: %s", attrib.IAttribID, err)
 	}
 
-	client := dynamodb.NewFromConfig(cfg)
+	return nameItem(item), nil
+}
 
-	// client := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
-	// 	o.BaseEndpoint = aws.String("http://localhost:4000")
-	// })
+// RecipientItem converts a mailing list recipient into the item stored in
+// the table.
+func RecipientItem(rcpt reldb.Recipient) (map[string]types.AttributeValue, error) {
 
-	written := 0
-	batchSize := 25 // DynamoDB allows a maximum batch size of 25 items.
-	start := 0
-	end := start + batchSize
-	for start < maxCats && start < len(categories) {
-
-		var writeReqs []types.WriteRequest
-		if end > len(categories) {
-			end = len(categories)
-		}
-
-		for _, category := range categories[start:end] {
-			catVal := CategoryValue{
-				PK:                "Category",
-				SK:                fmt.Sprintf("CAT#%d", category.IPCatID),
-				IPCatID:           category.IPCatID,
-				VCategoryName:     category.VName,
-				VCategoryURLName:  category.VURLName,
-				IParentID:         category.IParentID,
-				VShortDescription: category.VShortDesc,
-				MImages:           category.Images,
-				CTypeStatus:       fmt.Sprintf("C%s", category.CStatus),
-				IProductCount:     category.IProductCount,
-				LAttributes:       category.Attributes,
-				LChildren:         category.Children,
-			}
-
-			item, err = attributevalue.MarshalMap(catVal)
-			if err != nil {
-				log.Printf("Couldn't marshal category %v for batch writing. Here's why: %v\n", category.VName, err)
-			} else {
-				writeReqs = append(
-					writeReqs,
-					types.WriteRequest{PutRequest: &types.PutRequest{Item: item}},
-				)
-			}
-		}
-
-		requests := map[string][]types.WriteRequest{tableName: writeReqs}
-		_, err = client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
-			RequestItems: requests,
-		})
-		if err != nil {
-			log.Printf("Couldn't add a batch of categories to %v. Here's why: %v\n", tableName, err)
-		} else {
-			written += len(writeReqs)
-		}
-
-		start = end
-		end += batchSize
-
-		fmt.Printf("Sleeping for %s after adding %d entries\n", sleepBetweenBatches, start)
-		time.Sleep(sleepBetweenBatches)
+	rcpt.PK, rcpt.SK = RecipientKey(rcpt)
 
+	item, err := attributevalue.MarshalMap(rcpt)
+	if err != nil {
+		return nil, fmt.Errorf("error converting recipient %s to attribute-value: %s", rcpt.EMail, err)
 	}
 
-	return written, err
+	return nameItem(item), nil
 }
 
-// AddCategoryBatch adds a slice of categories to the DynamoDB table. The function sends
-// batches of 25 categories to DynamoDB until all categories are added or it reaches the
-// specified maximum.
-func AddProductBatch(ctx context.Context, products []reldb.Product, maxCats int) (int, error) {
+func PutCategory(ctx context.Context, cat reldb.CategorySummary) error {
 
 	cfg, err := config.Configuration()
 	if err != nil {
-		return 0, fmt.Errorf("error fetching default configuration: %s", err)
+		log.Fatalf("error fetching default configuration: %s", err)
 	}
 
 	client := dynamodb.NewFromConfig(cfg)
 
 	// client := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
-	// 	o.BaseEndpoint = aws.String("http://localhost:4000")
+	// 	o.BaseEndpoint = aws.String("https://localhost:4000")
 	// })
 
-	written := 0
-	batchSize := 25 // DynamoDB allows a maximum batch size of 25 items.
-	start := 0
-	end := start + batchSize
-	for start < maxCats && start < len(products) {
-
-		var writeReqs []types.WriteRequest
-		if end > len(products) {
-			end = len(products)
-		}
-
-		for _, product := range products[start:end] {
-			prodVal := ProductValue{
-				PK:                fmt.Sprintf("%sPROD%d", *product.CStatus, product.IProdID),
-				SK:                fmt.Sprintf("CAT#%d", product.IPCatID),
-				IPCatID:           product.IPCatID,
-				VName:             product.VName,
-				VURLName:          product.VURLName,
-				VCategoryName:     product.VCategoryName,
-				VCategoryURLName:  product.VCategoryURLName,
-				CCode:             product.CCode,
-				VShortDescription: product.VShortDesc,
-				VDescription:      product.VDescription,
-				MImages:           product.Images,
-				MPrices:           product.ProdPrice,
-				CTypeStatus:       fmt.Sprintf("P%s", *product.CStatus),
-				LAttributes:       product.Attributes,
-				LSKUs:             product.SKUs,
-				VYTID:             product.VYTID,
-			}
-
-			item, err := attributevalue.MarshalMap(prodVal)
-			if err != nil {
-				log.Printf("Couldn't marshal category %v for batch writing. Here's why: %v\n", product.VName, err)
-			} else {
-				writeReqs = append(
-					writeReqs,
-					types.WriteRequest{PutRequest: &types.PutRequest{Item: item}},
-				)
-			}
-		}
-
-		requests := map[string][]types.WriteRequest{tableName: writeReqs}
-		_, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
-			RequestItems: requests,
-		})
-		if err != nil {
-			log.Printf("Couldn't add a batch of products to %v. Here's why: %v\n", tableName, err)
-		} else {
-			written += len(writeReqs)
-		}
-
-		start = end
-		end += batchSize
-
-		fmt.Printf("Sleeping for %s after adding %d entries\n", sleepBetweenBatches, start)
-		time.Sleep(sleepBetweenBatches)
+	av, err := CategoryItem(cat)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("error adding category: %s", err)
 	}
 
-	return written, err
+	return nil
 }
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestFacetItemOpenBucket(t *testing.T) {
+
+	defer func(naming Naming) { ItemNaming = naming }(ItemNaming)
+	ItemNaming = NamingCamel
+
+	to := reldb.Money(50000)
+	item, err := FacetItem(reldb.CategorySummary{
+		IPCatID: 3,
+		PriceBuckets: []reldb.PriceBucket{
+			{FTo: &to, ICount: 2, ISubtreeCount: 2},
+			{FFrom: 50000, ICount: 1, ISubtreeCount: 1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	toName := attributeNameIndex["FTo"].Name(ItemNaming)
+	buckets := item[attributeNameIndex["LPriceBuckets"].Name(ItemNaming)].(*types.AttributeValueMemberL).Value
+	first := buckets[0].(*types.AttributeValueMemberM).Value
+	if n, ok := first[toName].(*types.AttributeValueMemberN); !ok || n.Value != "500.00" {
+		t.Errorf("first bucket %s = %#v, want 500.00", toName, first[toName])
+	}
+	last := buckets[1].(*types.AttributeValueMemberM).Value
+	if av, ok := last[toName]; ok {
+		t.Errorf("last bucket has %s %#v, want none", toName, av)
+	}
+}
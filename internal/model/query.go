@@ -0,0 +1,76 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// queryItems returns every item of the partition pk whose SK starts with
+// skPrefix, following the pages of the query.
+func (basics TableBasics) queryItems(ctx context.Context, pk, skPrefix string) ([]map[string]types.AttributeValue, error) {
+
+	paginator := dynamodb.NewQueryPaginator(basics.DynamoDbClient, &dynamodb.QueryInput{
+		TableName:              aws.String(basics.TableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: pk},
+			":sk": &types.AttributeValueMemberS{Value: skPrefix},
+		},
+	})
+
+	var items []map[string]types.AttributeValue
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error querying %s items: %s", pk, err)
+		}
+		items = append(items, page.Items...)
+	}
+
+	return items, nil
+}
+
+// StoredCategories reads back the category items of the table, decoded
+// under the current ItemNaming, in the order of their SK. Children lists
+// split into part items are joined back together.
+func (basics TableBasics) StoredCategories(ctx context.Context) ([]reldb.CategorySummary, error) {
+
+	items, err := basics.queryItems(ctx, "Category", "CAT#")
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeCategories(items)
+}
+
+// decodeCategories joins the part items of split categories into their
+// category and decodes the categories, skipping anything else that
+// shares the CAT# prefix.
+func decodeCategories(items []map[string]types.AttributeValue) ([]reldb.CategorySummary, error) {
+
+	items, err := JoinParts(items)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]reldb.CategorySummary, 0, len(items))
+	for _, item := range items {
+		if _, sk := itemKey(item); strings.Count(sk, "#") != 1 {
+			continue
+		}
+		cat, err := CategoryCodec{}.Decode(item)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, cat)
+	}
+
+	return categories, nil
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// splitCategoryItems migrates a root with enough children, each with a
+// long description, that the root item has to be split.
+func splitCategoryItems(t *testing.T) ([]reldb.CategorySummary, []map[string]types.AttributeValue) {
+
+	t.Helper()
+
+	desc := strings.Repeat("d", 2000)
+	root := reldb.CategorySummary{IPCatID: 0, VName: "Root", VURLName: "root"}
+	categories := []reldb.CategorySummary{}
+	for id := uint32(1); id <= 300; id++ {
+		child := reldb.CategorySummary{
+			IPCatID:    id,
+			VName:      fmt.Sprintf("Category %d", id),
+			VURLName:   fmt.Sprintf("category-%d", id),
+			VShortDesc: &desc,
+			CStatus:    "A",
+		}
+		categories = append(categories, child)
+		root.Children = append(root.Children, &categories[len(categories)-1])
+	}
+	categories = append([]reldb.CategorySummary{root}, categories...)
+
+	items := []map[string]types.AttributeValue{}
+	for _, cat := range categories {
+		item, err := CategoryItem(cat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+
+	fitted, rejected := FitItems("category", items, OverflowSplit, nil)
+	if len(rejected) > 0 {
+		t.Fatalf("split rejected %d items: %v", len(rejected), rejected[0].Error)
+	}
+	if len(fitted) <= len(items) {
+		t.Fatalf("root item was not split: %d items for %d categories", len(fitted), len(items))
+	}
+
+	return categories, fitted
+}
+
+func TestDecodeSplitCategories(t *testing.T) {
+
+	defer func(naming Naming) { ItemNaming = naming }(ItemNaming)
+
+	for _, naming := range []Naming{NamingField, NamingCamel, NamingSource, NamingShort} {
+		t.Run(string(naming), func(t *testing.T) {
+
+			ItemNaming = naming
+			categories, items := splitCategoryItems(t)
+
+			stored, err := decodeCategories(items)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stored) != len(categories) {
+				t.Fatalf("decoded %d categories, want %d", len(stored), len(categories))
+			}
+
+			seen := map[uint32]bool{}
+			for _, cat := range stored {
+				if seen[cat.IPCatID] {
+					t.Fatalf("category %d decoded twice", cat.IPCatID)
+				}
+				seen[cat.IPCatID] = true
+				if cat.VName == "" {
+					t.Fatalf("category %d decoded without a name", cat.IPCatID)
+				}
+				if cat.IPCatID == 0 && len(cat.Children) != len(categories)-1 {
+					t.Fatalf("root has %d children after joining, want %d", len(cat.Children), len(categories)-1)
+				}
+			}
+		})
+	}
+}
+
+func TestRollupOverSplitCategories(t *testing.T) {
+
+	categories, items := splitCategoryItems(t)
+	stored, err := decodeCategories(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []reldb.ProductChange{
+		{IProdID: 1, From: reldb.ProductState{IPCatID: 7, CStatus: "A"}, To: reldb.ProductState{IPCatID: 9, CStatus: "A"}},
+		{IProdID: 2, To: reldb.ProductState{IPCatID: 9, CStatus: "A"}},
+	}
+	got := reldb.CountDeltas(changes, stored, true)
+	want := []reldb.CountDelta{
+		{IPCatID: 0, ISubtreeCount: 1},
+		{IPCatID: 7, IProductCount: -1, ISubtreeCount: -1},
+		{IPCatID: 9, IProductCount: 2, ISubtreeCount: 2},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("deltas over %d categories = %v, want %v", len(categories), got, want)
+	}
+}
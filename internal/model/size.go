@@ -0,0 +1,420 @@
+package model
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MaxItemSize is the largest item DynamoDB accepts, attribute names
+// included.
+const MaxItemSize = 400 * 1024
+
+// compressMinSize is the smallest string attribute worth compressing.
+const compressMinSize = 1024
+
+// CompressedAttribute lists the attributes of an item that were gzipped
+// into binary by the compress overflow strategy.
+const CompressedAttribute = "CompressedAttributes"
+
+// PartsSuffix is appended to the name of a list attribute moved out of an
+// item by the split overflow strategy. The attribute holds the number of
+// part items, which share the PK of the item and have the SK
+// <SK>#<attribute>#<part>.
+const PartsSuffix = "Parts"
+
+// ItemSize estimates the stored size of an item the way DynamoDB
+// calculates it: the UTF-8 length of every attribute name plus the size of
+// its value.
+func ItemSize(item map[string]types.AttributeValue) int {
+
+	size := 0
+	for name, av := range item {
+		size += len(name) + valueSize(av)
+	}
+
+	return size
+}
+
+func valueSize(av types.AttributeValue) int {
+
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return numberSize(v.Value)
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberSS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		size := 0
+		for _, n := range v.Value {
+			size += numberSize(n)
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		size := 0
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := 3
+		for _, elem := range v.Value {
+			size += 1 + valueSize(elem)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		size := 3
+		for name, elem := range v.Value {
+			size += 1 + len(name) + valueSize(elem)
+		}
+		return size
+	}
+
+	return 0
+}
+
+// numberSize is roughly one byte per two significant digits plus one.
+func numberSize(n string) int {
+
+	digits := strings.Trim(strings.TrimLeft(n, "-+0"), ".")
+	if i := strings.IndexAny(digits, "eE"); i >= 0 {
+		digits = digits[:i]
+	}
+	digits = strings.Replace(digits, ".", "", 1)
+
+	return (len(digits)+1)/2 + 1
+}
+
+// OverflowStrategy decides what happens to an item larger than MaxItemSize.
+type OverflowStrategy string
+
+const (
+	// OverflowNone leaves the item as is, it fails as a dead letter.
+	OverflowNone OverflowStrategy = "none"
+	// OverflowCompress gzips the longest string attributes into binary,
+	// largest first, until the item fits.
+	OverflowCompress OverflowStrategy = "compress"
+	// OverflowSplit moves the largest list attribute into part items in
+	// the same collection.
+	OverflowSplit OverflowStrategy = "split"
+)
+
+// ParseOverflowStrategy checks a strategy given on the command line.
+func ParseOverflowStrategy(s string) (OverflowStrategy, error) {
+
+	switch strategy := OverflowStrategy(s); strategy {
+	case OverflowNone, OverflowCompress, OverflowSplit:
+		return strategy, nil
+	}
+
+	return "", fmt.Errorf("unknown overflow strategy %q (want none, compress or split)", s)
+}
+
+// Oversize records an item that was larger than MaxItemSize.
+type Oversize struct {
+	Entity    string           `json:"entity"`
+	PK        string           `json:"pk"`
+	SK        string           `json:"sk"`
+	Size      int              `json:"size"`
+	FinalSize int              `json:"finalSize"`
+	Strategy  OverflowStrategy `json:"strategy"`
+	Parts     int              `json:"parts,omitempty"`
+	Fits      bool             `json:"fits"`
+}
+
+// SizeReport collects the items that needed an overflow strategy.
+type SizeReport struct {
+	mu    sync.Mutex
+	Items []Oversize `json:"items"`
+}
+
+func (r *SizeReport) add(o Oversize) {
+
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Items = append(r.Items, o)
+}
+
+// Print writes the oversized items as a table.
+func (r *SizeReport) Print(w io.Writer) {
+
+	if r == nil || len(r.Items) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%d items were larger than %d bytes\n", len(r.Items), MaxItemSize)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENTITY\tPK\tSK\tSIZE\tSTRATEGY\tFINAL SIZE\tPARTS\tFITS")
+	for _, o := range r.Items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%d\t%d\t%t\n", o.Entity, o.PK, o.SK, o.Size, o.Strategy, o.FinalSize, o.Parts, o.Fits)
+	}
+	tw.Flush()
+}
+
+// FitItems applies strategy to every item of entity larger than
+// MaxItemSize and returns the items to write, part items included. Items
+// that still do not fit are returned as dead letters. Every oversized item
+// is recorded in report.
+func FitItems(entity string, items []map[string]types.AttributeValue, strategy OverflowStrategy, report *SizeReport) ([]map[string]types.AttributeValue, []DeadLetter) {
+
+	fitted := make([]map[string]types.AttributeValue, 0, len(items))
+	rejected := []DeadLetter{}
+	for _, item := range items {
+
+		size := ItemSize(item)
+		if size <= MaxItemSize {
+			fitted = append(fitted, item)
+			continue
+		}
+
+		var fits []map[string]types.AttributeValue
+		var err error
+		switch strategy {
+		case OverflowCompress:
+			var compressed map[string]types.AttributeValue
+			compressed, err = compressItem(item)
+			fits = []map[string]types.AttributeValue{compressed}
+		case OverflowSplit:
+			fits, err = splitItem(item)
+		default:
+			err = fmt.Errorf("item is %d bytes, no overflow strategy", size)
+		}
+
+		pk, sk := itemKey(item)
+		record := Oversize{Entity: entity, PK: pk, SK: sk, Size: size, Strategy: strategy}
+		if err == nil {
+			record.FinalSize = ItemSize(fits[0])
+			record.Parts = len(fits) - 1
+			record.Fits = true
+			fitted = append(fitted, fits...)
+		} else {
+			record.FinalSize = size
+			rejected = append(rejected, NewDeadLetter(entity, item, err, 1))
+		}
+		report.add(record)
+	}
+
+	return fitted, rejected
+}
+
+// compressItem gzips the string attributes of a copy of item, largest
+// first, until it fits.
+func compressItem(item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+
+	compressed := make(map[string]types.AttributeValue, len(item)+1)
+	candidates := []string{}
+	for name, av := range item {
+		compressed[name] = av
+		if s, ok := av.(*types.AttributeValueMemberS); ok && name != "PK" && name != "SK" && len(s.Value) >= compressMinSize {
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return valueSize(item[candidates[i]]) > valueSize(item[candidates[j]])
+	})
+
+	names := []string{}
+	for _, name := range candidates {
+		if ItemSize(compressed) <= MaxItemSize {
+			break
+		}
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write([]byte(item[name].(*types.AttributeValueMemberS).Value)); err != nil {
+			return nil, fmt.Errorf("error compressing %s: %w", name, err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("error compressing %s: %w", name, err)
+		}
+
+		compressed[name] = &types.AttributeValueMemberB{Value: buf.Bytes()}
+		names = append(names, name)
+		compressed[CompressedAttribute] = &types.AttributeValueMemberSS{Value: names}
+	}
+
+	if size := ItemSize(compressed); size > MaxItemSize {
+		return nil, fmt.Errorf("item is still %d bytes after compressing %v", size, names)
+	}
+
+	return compressed, nil
+}
+
+// DecompressAttribute undoes compressItem for one attribute.
+func DecompressAttribute(av types.AttributeValue) (string, error) {
+
+	b, ok := av.(*types.AttributeValueMemberB)
+	if !ok {
+		return "", fmt.Errorf("compressed attribute is not binary")
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(b.Value))
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+
+	s, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+
+	return string(s), nil
+}
+
+// splitItem moves the largest list attribute of a copy of item into part
+// items, each as large as fits, and returns the item followed by its
+// parts.
+func splitItem(item map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+
+	listName := ""
+	for name, av := range item {
+		if _, ok := av.(*types.AttributeValueMemberL); ok {
+			if listName == "" || valueSize(av) > valueSize(item[listName]) {
+				listName = name
+			}
+		}
+	}
+	if listName == "" {
+		return nil, fmt.Errorf("item has no list attribute to split")
+	}
+
+	pk, hasPK := item["PK"]
+	sk, hasSK := item["SK"].(*types.AttributeValueMemberS)
+	if !hasPK || !hasSK {
+		return nil, fmt.Errorf("item has no string PK and SK")
+	}
+
+	base := make(map[string]types.AttributeValue, len(item))
+	for name, av := range item {
+		if name != listName {
+			base[name] = av
+		}
+	}
+
+	// Every part holds PK, SK (<SK>#<list>#NNN), its index and the list
+	partOverhead := len("PK") + valueSize(pk) +
+		len("SK") + len(sk.Value) + len(listName) + 8 +
+		len("Part") + 3 +
+		len(listName) + 3
+	parts := []map[string]types.AttributeValue{}
+	elems := item[listName].(*types.AttributeValueMemberL).Value
+	current := []types.AttributeValue{}
+	currentSize := partOverhead
+	flush := func() {
+		parts = append(parts, map[string]types.AttributeValue{
+			"PK":     pk,
+			"SK":     &types.AttributeValueMemberS{Value: fmt.Sprintf("%s#%s#%03d", sk.Value, listName, len(parts))},
+			"Part":   &types.AttributeValueMemberN{Value: fmt.Sprint(len(parts))},
+			listName: &types.AttributeValueMemberL{Value: current},
+		})
+		current = []types.AttributeValue{}
+		currentSize = partOverhead
+	}
+	for i, elem := range elems {
+		elemSize := 1 + valueSize(elem)
+		if partOverhead+elemSize > MaxItemSize {
+			return nil, fmt.Errorf("element %d of %s alone is %d bytes", i, listName, elemSize)
+		}
+		if currentSize+elemSize > MaxItemSize {
+			flush()
+		}
+		current = append(current, elem)
+		currentSize += elemSize
+	}
+	if len(current) > 0 {
+		flush()
+	}
+
+	base[listName+PartsSuffix] = &types.AttributeValueMemberN{Value: fmt.Sprint(len(parts))}
+	if size := ItemSize(base); size > MaxItemSize {
+		return nil, fmt.Errorf("item is still %d bytes without %s", size, listName)
+	}
+
+	return append([]map[string]types.AttributeValue{base}, parts...), nil
+}
+
+// JoinParts undoes the split overflow strategy: it drops the part items
+// from items and puts their lists back into the items they were split
+// from, in part order. Items that were not split are returned as they are.
+func JoinParts(items []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+
+	parts := map[string][]map[string]types.AttributeValue{}
+	whole := make([]map[string]types.AttributeValue, 0, len(items))
+	for _, item := range items {
+		if _, isPart := item["Part"]; !isPart {
+			whole = append(whole, item)
+			continue
+		}
+		pk, sk := itemKey(item)
+		base := sk
+		if i := strings.LastIndex(sk, "#"); i >= 0 {
+			base = sk[:i]
+		}
+		for name := range item {
+			if name != "PK" && name != "SK" && name != "Part" {
+				base = strings.TrimSuffix(base, "#"+name)
+			}
+		}
+		parts[pk+"\x00"+base] = append(parts[pk+"\x00"+base], item)
+	}
+
+	for i, item := range whole {
+		pk, sk := itemKey(item)
+		itemParts := parts[pk+"\x00"+sk]
+		if len(itemParts) == 0 {
+			continue
+		}
+		sort.Slice(itemParts, func(a, b int) bool {
+			_, skA := itemKey(itemParts[a])
+			_, skB := itemKey(itemParts[b])
+			return skA < skB
+		})
+
+		joined := make(map[string]types.AttributeValue, len(item))
+		for name, av := range item {
+			joined[name] = av
+		}
+		for _, part := range itemParts {
+			for name, av := range part {
+				if name == "PK" || name == "SK" || name == "Part" {
+					continue
+				}
+				list, ok := av.(*types.AttributeValueMemberL)
+				if !ok {
+					return nil, fmt.Errorf("part of %s/%s has %s that is not a list", pk, sk, name)
+				}
+				existing, _ := joined[name].(*types.AttributeValueMemberL)
+				if existing == nil {
+					existing = &types.AttributeValueMemberL{}
+				}
+				joined[name] = &types.AttributeValueMemberL{Value: append(append([]types.AttributeValue{}, existing.Value...), list.Value...)}
+				delete(joined, name+PartsSuffix)
+			}
+		}
+		whole[i] = joined
+	}
+
+	return whole, nil
+}
@@ -0,0 +1,178 @@
+package quality
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+)
+
+// Policy decides what happens to a source row that breaks a rule.
+type Policy string
+
+const (
+	// PolicyDefault repairs the row when the rule knows how to, and skips
+	// it otherwise.
+	PolicyDefault Policy = "default"
+	// PolicySkip skips every row that breaks any rule.
+	PolicySkip Policy = "skip"
+)
+
+// ParsePolicy checks a policy given on the command line.
+func ParsePolicy(s string) (Policy, error) {
+
+	switch p := Policy(s); p {
+	case PolicyDefault, PolicySkip:
+		return p, nil
+	}
+
+	return "", fmt.Errorf("unknown policy %q (want %s or %s)", s, PolicyDefault, PolicySkip)
+}
+
+// Actions recorded in a Violation.
+const (
+	ActionDefaulted = "defaulted"
+	ActionSkipped   = "skipped"
+)
+
+// Rule is a check on a source row of type T. Default, when set, repairs a
+// row that fails Valid; rules without Default can only skip the row.
+type Rule[T any] struct {
+	Name    string
+	Valid   func(row T) bool
+	Default func(row *T)
+}
+
+// Violation records one row breaking one rule.
+type Violation struct {
+	Entity string `json:"entity"`
+	RowID  string `json:"rowId"`
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+}
+
+// Report collects the violations of a migration run.
+type Report struct {
+	mu         sync.Mutex
+	Violations []Violation `json:"violations"`
+}
+
+func (r *Report) add(v Violation) {
+
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Violations = append(r.Violations, v)
+}
+
+// Apply checks every row against the rules and returns the rows to keep,
+// repaired where the policy allows. Every rule a row breaks is recorded in
+// report, identified by id(row).
+func Apply[T any](entity string, rows []T, id func(T) string, rules []Rule[T], policy Policy, report *Report) []T {
+
+	kept := make([]T, 0, len(rows))
+	for _, row := range rows {
+
+		skip := false
+		for _, rule := range rules {
+			if rule.Valid(row) {
+				continue
+			}
+
+			action := ActionSkipped
+			if policy == PolicyDefault && rule.Default != nil && !skip {
+				rule.Default(&row)
+				action = ActionDefaulted
+			} else {
+				skip = true
+			}
+			report.add(Violation{Entity: entity, RowID: id(row), Rule: rule.Name, Action: action})
+		}
+
+		if !skip {
+			kept = append(kept, row)
+		}
+	}
+
+	return kept
+}
+
+// Print writes the violations grouped by entity and rule.
+func (r *Report) Print(w io.Writer) {
+
+	if r == nil || len(r.Violations) == 0 {
+		fmt.Fprintln(w, "Data quality: no violations")
+		return
+	}
+
+	type group struct {
+		entity, rule, action string
+	}
+	rowIDs := map[group][]string{}
+	for _, v := range r.Violations {
+		g := group{v.Entity, v.Rule, v.Action}
+		rowIDs[g] = append(rowIDs[g], v.RowID)
+	}
+
+	groups := make([]group, 0, len(rowIDs))
+	for g := range rowIDs {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].entity != groups[j].entity {
+			return groups[i].entity < groups[j].entity
+		}
+		if groups[i].rule != groups[j].rule {
+			return groups[i].rule < groups[j].rule
+		}
+		return groups[i].action < groups[j].action
+	})
+
+	fmt.Fprintf(w, "Data quality: %d violations\n", len(r.Violations))
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENTITY\tRULE\tACTION\tROWS\tIDS")
+	for _, g := range groups {
+		ids := rowIDs[g]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", g.entity, g.rule, g.action, len(ids), joinIDs(ids, 20))
+	}
+	tw.Flush()
+}
+
+// joinIDs lists at most max ids, the full list is in the JSON report.
+func joinIDs(ids []string, max int) string {
+
+	s := ""
+	for i, id := range ids {
+		if i == max {
+			return fmt.Sprintf("%s, ... (%d more)", s, len(ids)-max)
+		}
+		if i > 0 {
+			s += ", "
+		}
+		s += id
+	}
+
+	return s
+}
+
+// WriteJSON writes every violation to fileName.
+func (r *Report) WriteJSON(fileName string) error {
+
+	jsonBytes, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return fmt.Errorf("error marshaling data quality report: %w", err)
+	}
+
+	if err := os.WriteFile(fileName, jsonBytes, 0644); err != nil {
+		return fmt.Errorf("error writing data quality report %s: %w", fileName, err)
+	}
+
+	return nil
+}
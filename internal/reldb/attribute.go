@@ -1,5 +1,11 @@
 package reldb
 
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
 type Attribute struct {
 	IAttribID      int32  `db:"iAttribID" json:"iAttribID"`
 	VName          string `db:"vName" json:"vName"`
@@ -7,3 +13,55 @@ type Attribute struct {
 	CFilterDisplay string `db:"cFilterDisplay" json:"cFilterDisplay"`
 	CStatus        string `db:"cStatus" json:"cStatus"`
 }
+
+// IsFilter tells if the storefront shows the attribute as a filter facet.
+// cFilterDisplay is the display mode, N or empty for none.
+func (a Attribute) IsFilter() bool {
+	return a.CStatus == "A" && a.CFilterDisplay != "" && a.CFilterDisplay != "N"
+}
+
+// Attributes returns the attribute master in rank order.
+func (m *Model) Attributes(ctx context.Context) ([]Attribute, error) {
+
+	query := `SELECT
+				iAttribID,
+				vName,
+				iRank,
+				COALESCE(cFilterDisplay, 'N') cFilterDisplay,
+				COALESCE(cStatus, 'I') cStatus
+			FROM
+				attribute
+			ORDER BY
+				iRank,
+				iAttribID`
+
+	attributes := []Attribute{}
+	if err := m.SelectContext(ctx, &attributes, query); err != nil {
+		return nil, fmt.Errorf("error fetching attributes: %s", err)
+	}
+
+	return attributes, nil
+}
+
+// categoryFacets returns the iAttribIDs of the filter attributes among the
+// attributes of a category, in attribute rank order.
+func categoryFacets(attribs []CategoryAttribute, master map[uint32]Attribute) []uint32 {
+
+	facets := []uint32{}
+	seen := map[uint32]bool{}
+	for _, attrib := range attribs {
+		if a, ok := master[attrib.IAttribID]; ok && a.IsFilter() && !seen[attrib.IAttribID] {
+			seen[attrib.IAttribID] = true
+			facets = append(facets, attrib.IAttribID)
+		}
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		ri, rj := master[facets[i]].IRank, master[facets[j]].IRank
+		if ri != rj {
+			return ri < rj
+		}
+		return facets[i] < facets[j]
+	})
+
+	return facets
+}
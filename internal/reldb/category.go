@@ -1,7 +1,11 @@
 package reldb
 
 import (
+	"cmp"
+	"context"
 	"fmt"
+	"slices"
+	"strings"
 )
 
 type CategorySummary struct {
@@ -11,9 +15,16 @@ type CategorySummary struct {
 	IParentID  uint32  `db:"iParentID"`
 	VShortDesc *string `db:"vShortDesc"`
 	Images
+	ImagesMeta    *ImagesMeta         `db:"-" dynamodbav:"-"`
 	CStatus       string              `db:"cStatus"`
+	IRank         int                 `db:"iRank"`
 	IProductCount int                 `db:"iProductCount"`
+	ISubtreeCount int                 `db:"-"` // active products including descendants
 	Attributes    []CategoryAttribute `db:"-"`
+	Facets        []uint32            `db:"-"` // iAttribIDs of the filters to show
+	FacetCounts   []FacetCount        `db:"-" dynamodbav:"-"`
+	PriceBuckets  []PriceBucket       `db:"-" dynamodbav:"-"`
+	Path          []Crumb             `db:"-" dynamodbav:"-"`
 	Children      []*CategorySummary  `db:"-"`
 }
 
@@ -26,7 +37,7 @@ type CategoryAttribute struct {
 	IRank        int     `db:"iRank" json:"iRank" diff:"iRank"`
 }
 
-func (m *Model) CategoryMaster() (map[uint32]*CategorySummary, error) {
+func (m *Model) CategoryMaster(ctx context.Context) (map[uint32]*CategorySummary, error) {
 
 	query := `SELECT
 				c.iPCatID,
@@ -37,6 +48,7 @@ func (m *Model) CategoryMaster() (map[uint32]*CategorySummary, error) {
 				c.vMenuImage vImage,
 				c.vMenuImage_AltTag vImage_AltTag,
 				COALESCE(c.cStatus, 'I') cStatus,
+				COALESCE(c.iRank, 0) iRank,
 				SUM(CASE WHEN p.cStatus = 'A' THEN 1 ELSE 0 END) iProductCount
 			FROM
 				prodcat c LEFT JOIN
@@ -49,11 +61,12 @@ func (m *Model) CategoryMaster() (map[uint32]*CategorySummary, error) {
 				c.vShortDesc,
 				c.vMenuImage,
 				c.vMenuImage_AltTag,
-				c.cStatus
+				c.cStatus,
+				c.iRank
 			ORDER BY
 				c.iPCatID`
 	var categories []CategorySummary
-	if err := m.Select(&categories, query); err != nil {
+	if err := m.SelectContext(ctx, &categories, query); err != nil {
 		return nil, err
 	}
 
@@ -73,7 +86,7 @@ func (m *Model) CategoryMaster() (map[uint32]*CategorySummary, error) {
 	return cMap, nil
 }
 
-func (m *Model) CategoryAttributes() (map[uint32][]CategoryAttribute, error) {
+func (m *Model) CategoryAttributes(ctx context.Context) (map[uint32][]CategoryAttribute, error) {
 
 	catAttrMap := make(map[uint32][]CategoryAttribute)
 	var categoryAttribs []CategoryAttribute
@@ -89,9 +102,9 @@ func (m *Model) CategoryAttributes() (map[uint32][]CategoryAttribute, error) {
     			LEFT JOIN prodcat_attrib_dat pca ON c.iPCatID = pca.iPCatID
 				JOIN attribute a ON pca.iAttribID = a.iAttribID
 			
-			ORDER BY c.iPCatID`
+			ORDER BY c.iPCatID, pca.iAttribDatID`
 
-	if err := m.Select(&categoryAttribs, query); err != nil {
+	if err := m.SelectContext(ctx, &categoryAttribs, query); err != nil {
 		return nil, err
 	}
 
@@ -108,23 +121,119 @@ func (m *Model) CategoryAttributes() (map[uint32][]CategoryAttribute, error) {
 	return catAttrMap, nil
 }
 
-func (m *Model) CategoryTree() ([]CategorySummary, error) {
+// CategoryOrder is the order of the children of a category in CategoryTree.
+// Ties are broken by ID, so the order is the same on every run.
+type CategoryOrder string
 
-	catSummMap, err := m.CategoryMaster()
+const (
+	CategoryOrderRank CategoryOrder = "rank"
+	CategoryOrderName CategoryOrder = "name"
+	CategoryOrderID   CategoryOrder = "id"
+)
+
+// ParseCategoryOrder checks an order given on the command line.
+func ParseCategoryOrder(s string) (CategoryOrder, error) {
+
+	switch order := CategoryOrder(s); order {
+	case CategoryOrderRank, CategoryOrderName, CategoryOrderID:
+		return order, nil
+	}
+
+	return "", fmt.Errorf("unknown category order %q (want rank, name or id)", s)
+}
+
+func (o CategoryOrder) compare(a, b *CategorySummary) int {
+
+	switch o {
+	case CategoryOrderRank:
+		if c := cmp.Compare(a.IRank, b.IRank); c != 0 {
+			return c
+		}
+	case CategoryOrderName:
+		if c := strings.Compare(a.VName, b.VName); c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(a.IPCatID, b.IPCatID)
+}
+
+// CategoryTree returns every category with its attributes and path. The
+// first is the synthetic root, whose children make up the tree, the rest
+// follow depth first, children ordered by m.CategoryOrder (rank by
+// default).
+func (m *Model) CategoryTree(ctx context.Context) ([]CategorySummary, error) {
+	return m.categoryTree(ctx, false)
+}
+
+// CategoryTreeWithFacets is CategoryTree with the facet counts and price
+// buckets of every category, which count every active product.
+func (m *Model) CategoryTreeWithFacets(ctx context.Context) ([]CategorySummary, error) {
+	return m.categoryTree(ctx, true)
+}
+
+func (m *Model) categoryTree(ctx context.Context, withFacets bool) ([]CategorySummary, error) {
+
+	catSummMap, err := m.CategoryMaster(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching category summary: %s", err)
 	}
 
 	// Keep attributes and children
 	// ready to assign to categories
-	attribs, err := m.CategoryAttributes()
+	attribs, err := m.CategoryAttributes(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching category attributes: %s", err)
 	}
 
-	for iPCatID, category := range catSummMap {
+	attributes, err := m.Attributes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	master := make(map[uint32]Attribute, len(attributes))
+	for _, a := range attributes {
+		master[uint32(a.IAttribID)] = a
+	}
+
+	order := m.CategoryOrder
+	if order == "" {
+		order = CategoryOrderRank
+	}
+	tree := assembleCategoryTree(catSummMap, attribs, master, order)
+
+	if withFacets {
+		if err := m.addFacetCounts(ctx, catSummMap, master); err != nil {
+			return nil, err
+		}
+	}
+
+	categories := make([]CategorySummary, 0, len(tree))
+	for _, category := range tree {
+		categories = append(categories, *category)
+	}
+
+	return categories, nil
+}
+
+// assembleCategoryTree links the categories of CategoryMaster into a tree
+// with their attributes, facets, path and subtree counts, and returns them
+// in the order of CategoryTree: the root, the rest depth first in child
+// order, then anything not reachable from the root by ID.
+func assembleCategoryTree(catSummMap map[uint32]*CategorySummary, attribs map[uint32][]CategoryAttribute, master map[uint32]Attribute, order CategoryOrder) []*CategorySummary {
 
+	// Visit categories by ID so that children are added in the same
+	// order on every run, then sort them
+	ids := make([]uint32, 0, len(catSummMap))
+	for iPCatID := range catSummMap {
+		ids = append(ids, iPCatID)
+	}
+	slices.Sort(ids)
+
+	for _, iPCatID := range ids {
+
+		category := catSummMap[iPCatID]
 		category.Attributes = attribs[iPCatID]
+		category.Facets = categoryFacets(category.Attributes, master)
 
 		if iPCatID > 0 {
 			parent, hasParent := catSummMap[category.IParentID]
@@ -137,16 +246,32 @@ func (m *Model) CategoryTree() ([]CategorySummary, error) {
 		}
 	}
 
-	// we want to send the tree root
-	// as the first element of this slice
-	categories := []CategorySummary{*catSummMap[0]}
-	// and remove that key from the map
-	delete(catSummMap, 0)
+	for _, category := range catSummMap {
+		slices.SortStableFunc(category.Children, order.compare)
+	}
 
-	// Now add the rest in
-	for _, cat := range catSummMap {
-		categories = append(categories, *cat)
+	for iPCatID, category := range catSummMap {
+		category.Path = categoryPath(catSummMap, iPCatID)
 	}
+	addSubtreeCounts(catSummMap)
 
-	return categories, nil
+	tree := make([]*CategorySummary, 0, len(catSummMap))
+	added := map[uint32]bool{}
+	var addTree func(category *CategorySummary)
+	addTree = func(category *CategorySummary) {
+		if added[category.IPCatID] {
+			return
+		}
+		added[category.IPCatID] = true
+		tree = append(tree, category)
+		for _, child := range category.Children {
+			addTree(child)
+		}
+	}
+	addTree(catSummMap[0])
+	for _, iPCatID := range ids {
+		addTree(catSummMap[iPCatID])
+	}
+
+	return tree
 }
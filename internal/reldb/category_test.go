@@ -0,0 +1,94 @@
+package reldb
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// categoryFixture is what CategoryTree fetches: the rows of CategoryMaster,
+// including its synthetic root, CategoryAttributes and Attributes.
+type categoryFixture struct {
+	Categories []CategorySummary   `json:"categories"`
+	Attributes []CategoryAttribute `json:"attributes"`
+	Master     []Attribute         `json:"master"`
+}
+
+func readCategoryFixture(t *testing.T) (map[uint32]*CategorySummary, map[uint32][]CategoryAttribute, map[uint32]Attribute) {
+
+	t.Helper()
+
+	jsonBytes, err := os.ReadFile(filepath.Join("testdata", "categories.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture := categoryFixture{}
+	if err := json.Unmarshal(jsonBytes, &fixture); err != nil {
+		t.Fatal(err)
+	}
+
+	catSummMap := map[uint32]*CategorySummary{}
+	for _, category := range fixture.Categories {
+		catSummMap[category.IPCatID] = &category
+	}
+	attribs := map[uint32][]CategoryAttribute{}
+	for _, attrib := range fixture.Attributes {
+		attribs[attrib.IPCatID] = append(attribs[attrib.IPCatID], attrib)
+	}
+	master := map[uint32]Attribute{}
+	for _, a := range fixture.Master {
+		master[uint32(a.IAttribID)] = a
+	}
+
+	return catSummMap, attribs, master
+}
+
+// renderTree writes one line per category, indented by the length of its
+// path.
+func renderTree(tree []*CategorySummary) string {
+
+	var b strings.Builder
+	for _, category := range tree {
+		children := make([]uint32, len(category.Children))
+		for i, child := range category.Children {
+			children[i] = child.IPCatID
+		}
+		fmt.Fprintf(&b, "%s%d %s rank=%d products=%d subtree=%d facets=%v children=%v path=%s\n",
+			strings.Repeat("  ", len(category.Path)), category.IPCatID, category.VName, category.IRank,
+			category.IProductCount, category.ISubtreeCount, category.Facets, children, PathURL(category.Path))
+	}
+
+	return b.String()
+}
+
+func TestAssembleCategoryTree(t *testing.T) {
+
+	for _, order := range []CategoryOrder{CategoryOrderRank, CategoryOrderName, CategoryOrderID} {
+		t.Run(string(order), func(t *testing.T) {
+
+			catSummMap, attribs, master := readCategoryFixture(t)
+			got := []byte(renderTree(assembleCategoryTree(catSummMap, attribs, master, order)))
+
+			golden := filepath.Join("testdata", "category_tree_"+string(order)+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("tree in %s order differs from %s\n got:\n%s\nwant:\n%s", order, golden, got, want)
+			}
+		})
+	}
+}
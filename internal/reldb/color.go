@@ -0,0 +1,62 @@
+package reldb
+
+import (
+	"context"
+	"fmt"
+)
+
+// Colors returns the color master in display order.
+func (m *Model) Colors(ctx context.Context) ([]Color, error) {
+
+	query := `SELECT
+				iColorID,
+				vName,
+				COALESCE(vColor, '') vColor,
+				iRank,
+				COALESCE(cStatus, 'I') cStatus
+			FROM
+				color
+			ORDER BY
+				iRank,
+				iColorID`
+
+	colors := []Color{}
+	if err := m.SelectContext(ctx, &colors, query); err != nil {
+		return nil, fmt.Errorf("error fetching colors: %s", err)
+	}
+
+	return colors, nil
+}
+
+// ProductColors returns the colors a product comes in keyed by iPCID.
+func (m *Model) ProductColors(ctx context.Context, iProdID uint32) (map[uint32]Color, error) {
+
+	query := `SELECT
+				pc.iPCID,
+				c.iColorID,
+				c.vName,
+				COALESCE(c.vColor, '') vColor,
+				c.iRank,
+				COALESCE(c.cStatus, 'I') cStatus
+			FROM
+				product_color pc
+			JOIN color c ON
+				pc.iColorID = c.iColorID
+			WHERE
+				pc.iProdID = ?`
+
+	rows := []struct {
+		IPCID uint32 `db:"iPCID"`
+		Color
+	}{}
+	if err := m.SelectContext(ctx, &rows, query, iProdID); err != nil {
+		return nil, fmt.Errorf("error fetching colors of product %d: %s", iProdID, err)
+	}
+
+	colors := make(map[uint32]Color, len(rows))
+	for _, row := range rows {
+		colors[row.IPCID] = row.Color
+	}
+
+	return colors, nil
+}
@@ -0,0 +1,173 @@
+package reldb
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ProductState is what decides where a product is counted: it counts in
+// IProductCount of its category when its status is A.
+type ProductState struct {
+	IPCatID uint32 `json:"iPCatID"`
+	CStatus string `json:"cStatus"`
+}
+
+func (s ProductState) active() bool {
+	return s.CStatus == "A"
+}
+
+// ProductChange is a product that was activated, deactivated or moved to
+// another category. A new product has a zero From, a deleted one a zero To.
+type ProductChange struct {
+	IProdID uint32       `json:"iProdID"`
+	From    ProductState `json:"from"`
+	To      ProductState `json:"to"`
+}
+
+// CountDelta is what the product counts of a category change by.
+type CountDelta struct {
+	IPCatID       uint32 `json:"iPCatID"`
+	IProductCount int    `json:"iProductCount"`
+	ISubtreeCount int    `json:"iSubtreeCount,omitempty"`
+}
+
+// CountDeltas nets changes into the deltas of every category whose counts
+// change, in category ID order. With rollup the ISubtreeCount of the
+// category and of each of its ancestors in categories changes as well.
+func CountDeltas(changes []ProductChange, categories []CategorySummary, rollup bool) []CountDelta {
+
+	catSummMap := make(map[uint32]*CategorySummary, len(categories))
+	for i := range categories {
+		catSummMap[categories[i].IPCatID] = &categories[i]
+	}
+
+	deltas := map[uint32]*CountDelta{}
+	add := func(iPCatID uint32, by int) {
+		delta := func(id uint32) *CountDelta {
+			if _, exists := deltas[id]; !exists {
+				deltas[id] = &CountDelta{IPCatID: id}
+			}
+			return deltas[id]
+		}
+		delta(iPCatID).IProductCount += by
+		if !rollup {
+			return
+		}
+		ids := ancestry(catSummMap, iPCatID)
+		if len(ids) == 0 {
+			// Not a known category, roll up to itself only
+			ids = []uint32{iPCatID}
+		}
+		for _, id := range ids {
+			delta(id).ISubtreeCount += by
+		}
+	}
+
+	for _, change := range changes {
+		if change.From == change.To {
+			continue
+		}
+		if change.From.active() {
+			add(change.From.IPCatID, -1)
+		}
+		if change.To.active() {
+			add(change.To.IPCatID, 1)
+		}
+	}
+
+	result := make([]CountDelta, 0, len(deltas))
+	for _, delta := range deltas {
+		if delta.IProductCount != 0 || delta.ISubtreeCount != 0 {
+			result = append(result, *delta)
+		}
+	}
+	slices.SortFunc(result, func(a, b CountDelta) int {
+		return cmp.Compare(a.IPCatID, b.IPCatID)
+	})
+
+	return result
+}
+
+// ReadProductChanges reads one ProductChange JSON object per line from
+// fileName, or from standard input when fileName is -.
+func ReadProductChanges(fileName string) ([]ProductChange, error) {
+	return readJSONLines[ProductChange](fileName, "product change")
+}
+
+// ReadCountDeltas reads one CountDelta JSON object per line, as written by
+// WriteCountDeltas, from fileName, or from standard input when fileName
+// is -.
+func ReadCountDeltas(fileName string) ([]CountDelta, error) {
+	return readJSONLines[CountDelta](fileName, "count delta")
+}
+
+// WriteCountDeltas writes deltas to fileName, one JSON object per line.
+func WriteCountDeltas(fileName string, deltas []CountDelta) error {
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating count deltas %s: %s", fileName, err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, delta := range deltas {
+		if err := encoder.Encode(delta); err != nil {
+			return fmt.Errorf("error writing count deltas %s: %s", fileName, err)
+		}
+	}
+
+	return file.Close()
+}
+
+func readJSONLines[T any](fileName string, what string) ([]T, error) {
+
+	var r io.Reader = os.Stdin
+	if fileName != "-" {
+		file, err := os.Open(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("error opening %ss %s: %s", what, fileName, err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	values := []T{}
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var value T
+		if err := json.Unmarshal([]byte(line), &value); err != nil {
+			return nil, fmt.Errorf("error decoding %s on line %d of %s: %s", what, lineNo, fileName, err)
+		}
+		values = append(values, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %ss %s: %s", what, fileName, err)
+	}
+
+	return values, nil
+}
+
+// addSubtreeCounts sets ISubtreeCount of every category to the active
+// products of the category and its descendants.
+func addSubtreeCounts(catSummMap map[uint32]*CategorySummary) {
+
+	for _, category := range catSummMap {
+		category.ISubtreeCount = 0
+	}
+	for iPCatID, category := range catSummMap {
+		for _, id := range ancestry(catSummMap, iPCatID) {
+			catSummMap[id].ISubtreeCount += category.IProductCount
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package reldb
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestCountDeltasRoundTrip checks that the deltas counts records as not
+// applied are read back unchanged to be fed again.
+func TestCountDeltasRoundTrip(t *testing.T) {
+
+	deltas := []CountDelta{
+		{IPCatID: 3, IProductCount: -2},
+		{IPCatID: 5, IProductCount: 1, ISubtreeCount: 1},
+		{IPCatID: 1, ISubtreeCount: -1},
+	}
+
+	fileName := filepath.Join(t.TempDir(), "unapplied.jsonl")
+	if err := WriteCountDeltas(fileName, deltas); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadCountDeltas(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, deltas) {
+		t.Errorf("ReadCountDeltas = %+v, want %+v", got, deltas)
+	}
+}
@@ -0,0 +1,216 @@
+package reldb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// FacetCount is the number of active products with a filter attribute
+// value, e.g. Size M (12). ICount counts the products of the category
+// itself, ISubtreeCount also those of its descendants.
+type FacetCount struct {
+	IAttribID     uint32
+	VAttribName   string
+	VValue        string
+	ICount        int
+	ISubtreeCount int
+}
+
+// PriceBucket counts the active products priced from FFrom up to, but not
+// including, FTo. FTo is nil for the last bucket, which has no upper
+// bound, so it is left out of the item.
+type PriceBucket struct {
+	FFrom         Money
+	FTo           *Money
+	ICount        int
+	ISubtreeCount int
+}
+
+// PriceBucketEdges are the upper bounds of the price buckets.
+var PriceBucketEdges = []Money{50000, 100000, 250000, 500000, 1000000}
+
+type facetRow struct {
+	IPCatID     uint32 `db:"iPCatID"`
+	IProdID     uint32 `db:"iProdID"`
+	IAttribID   uint32 `db:"iAttribID"`
+	VAttribName string `db:"vAttribName"`
+	VValue      string `db:"vValue"`
+}
+
+type priceRow struct {
+	IPCatID uint32 `db:"iPCatID"`
+	IProdID uint32 `db:"iProdID"`
+	FPrice  Money  `db:"fPrice"`
+}
+
+// addFacetCounts sets the facet counts and price buckets of every category
+// in catSummMap, whose parents must already be linked. Only filter
+// attributes of master are counted.
+func (m *Model) addFacetCounts(ctx context.Context, catSummMap map[uint32]*CategorySummary, master map[uint32]Attribute) error {
+
+	query := `SELECT
+				p.iPCatID,
+				p.iProdID,
+				pa.iAttribID,
+				a.vName vAttribName,
+				pa.vValue
+			FROM
+				product p JOIN
+				product_attrib pa ON p.iProdID = pa.iProdID JOIN
+				attribute a ON pa.iAttribID = a.iAttribID
+			WHERE
+				p.cStatus = 'A' AND
+				TRIM(COALESCE(pa.vValue, '')) <> ''
+			UNION ALL
+			SELECT
+				p.iPCatID,
+				p.iProdID,
+				` + fmt.Sprint(ColorAttribID) + ` iAttribID,
+				'Color' vAttribName,
+				c.vName vValue
+			FROM
+				product p JOIN
+				product_color pc ON p.iProdID = pc.iProdID JOIN
+				color c ON pc.iColorID = c.iColorID
+			WHERE
+				p.cStatus = 'A' AND
+				pc.cStatus = 'A'`
+
+	facetRows := []facetRow{}
+	if err := m.SelectContext(ctx, &facetRows, query); err != nil {
+		return fmt.Errorf("error fetching facet values: %s", err)
+	}
+
+	query = `SELECT
+				iPCatID,
+				iProdID,
+				fPrice
+			FROM
+				product
+			WHERE
+				cStatus = 'A'`
+
+	priceRows := []priceRow{}
+	if err := m.SelectContext(ctx, &priceRows, query); err != nil {
+		return fmt.Errorf("error fetching facet prices: %s", err)
+	}
+
+	// Products are counted once per value even if listed twice
+	type facetKey struct {
+		iAttribID uint32
+		vValue    string
+	}
+	type counts struct {
+		facets       map[facetKey]*FacetCount
+		ownProducts  map[facetKey]map[uint32]bool
+		treeProducts map[facetKey]map[uint32]bool
+		ownBuckets   []int
+		treeBuckets  []int
+	}
+	byCategory := map[uint32]*counts{}
+	categoryCounts := func(iPCatID uint32) *counts {
+		if byCategory[iPCatID] == nil {
+			byCategory[iPCatID] = &counts{
+				facets:       map[facetKey]*FacetCount{},
+				ownProducts:  map[facetKey]map[uint32]bool{},
+				treeProducts: map[facetKey]map[uint32]bool{},
+				ownBuckets:   make([]int, len(PriceBucketEdges)+1),
+				treeBuckets:  make([]int, len(PriceBucketEdges)+1),
+			}
+		}
+		return byCategory[iPCatID]
+	}
+
+	for _, row := range facetRows {
+		if a, ok := master[row.IAttribID]; !ok || !a.IsFilter() {
+			continue
+		}
+		key := facetKey{row.IAttribID, row.VValue}
+		for _, iPCatID := range ancestry(catSummMap, row.IPCatID) {
+			c := categoryCounts(iPCatID)
+			if c.facets[key] == nil {
+				c.facets[key] = &FacetCount{IAttribID: row.IAttribID, VAttribName: row.VAttribName, VValue: row.VValue}
+				c.ownProducts[key] = map[uint32]bool{}
+				c.treeProducts[key] = map[uint32]bool{}
+			}
+			if iPCatID == row.IPCatID {
+				c.ownProducts[key][row.IProdID] = true
+			}
+			c.treeProducts[key][row.IProdID] = true
+		}
+	}
+
+	for _, row := range priceRows {
+		bucket := sort.Search(len(PriceBucketEdges), func(i int) bool { return row.FPrice < PriceBucketEdges[i] })
+		for _, iPCatID := range ancestry(catSummMap, row.IPCatID) {
+			c := categoryCounts(iPCatID)
+			if iPCatID == row.IPCatID {
+				c.ownBuckets[bucket]++
+			}
+			c.treeBuckets[bucket]++
+		}
+	}
+
+	for iPCatID, c := range byCategory {
+		category, ok := catSummMap[iPCatID]
+		if !ok {
+			continue
+		}
+
+		category.FacetCounts = make([]FacetCount, 0, len(c.facets))
+		for key, facet := range c.facets {
+			facet.ICount = len(c.ownProducts[key])
+			facet.ISubtreeCount = len(c.treeProducts[key])
+			category.FacetCounts = append(category.FacetCounts, *facet)
+		}
+		sort.Slice(category.FacetCounts, func(i, j int) bool {
+			fi, fj := category.FacetCounts[i], category.FacetCounts[j]
+			if ri, rj := master[fi.IAttribID].IRank, master[fj.IAttribID].IRank; ri != rj {
+				return ri < rj
+			}
+			if fi.IAttribID != fj.IAttribID {
+				return fi.IAttribID < fj.IAttribID
+			}
+			return fi.VValue < fj.VValue
+		})
+
+		category.PriceBuckets = make([]PriceBucket, 0, len(c.ownBuckets))
+		for i := range c.ownBuckets {
+			if c.treeBuckets[i] == 0 {
+				continue
+			}
+			bucket := PriceBucket{ICount: c.ownBuckets[i], ISubtreeCount: c.treeBuckets[i]}
+			if i > 0 {
+				bucket.FFrom = PriceBucketEdges[i-1]
+			}
+			if i < len(PriceBucketEdges) {
+				to := PriceBucketEdges[i]
+				bucket.FTo = &to
+			}
+			category.PriceBuckets = append(category.PriceBuckets, bucket)
+		}
+	}
+
+	return nil
+}
+
+// ancestry returns iPCatID followed by the IDs of its ancestors up to the
+// root. It stops at a missing parent or a cycle.
+func ancestry(catSummMap map[uint32]*CategorySummary, iPCatID uint32) []uint32 {
+
+	ids := []uint32{}
+	seen := map[uint32]bool{}
+	for {
+		category, ok := catSummMap[iPCatID]
+		if !ok || seen[iPCatID] {
+			return ids
+		}
+		seen[iPCatID] = true
+		ids = append(ids, iPCatID)
+		if iPCatID == 0 {
+			return ids
+		}
+		iPCatID = category.IParentID
+	}
+}
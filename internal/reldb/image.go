@@ -33,7 +33,7 @@ type ProductImage struct {
 
 var decoder schema.Decoder = *schema.NewDecoder()
 
-func (m *Model) ProductImages(productID int32) ([]ProductImage, error) {
+func (m *Model) ProductImages(ctx context.Context, productID int32) ([]ProductImage, error) {
 
 	pImages := []ProductImage{}
 
@@ -55,13 +55,40 @@ func (m *Model) ProductImages(productID int32) ([]ProductImage, error) {
 				FROM product_images
 				WHERE iProdID = ?`
 
-	if err := m.Select(&pImages, query, productID, productID); err != nil {
+	if err := m.SelectContext(ctx, &pImages, query, productID, productID); err != nil {
 		return nil, err
 	}
 
 	return pImages, nil
 }
 
+// AdditionalImage is a row of product_images.
+type AdditionalImage struct {
+	IProdID uint32 `json:"iProdID" db:"iProdID"`
+	DbImage
+}
+
+// AdditionalImages returns the additional images of all products, by
+// product and image ID.
+func (m *Model) AdditionalImages(ctx context.Context) ([]AdditionalImage, error) {
+
+	query := `SELECT
+					iProdID,
+					iProdImageID,
+					vPic   as vName,
+					vTitle as vAltTag,
+					cStatus
+				FROM product_images
+				ORDER BY iProdID, iProdImageID`
+
+	images := []AdditionalImage{}
+	if err := m.SelectContext(ctx, &images, query); err != nil {
+		return nil, fmt.Errorf("error fetching additional images: %s", err)
+	}
+
+	return images, nil
+}
+
 var delOtherQry = "DELETE FROM product_images WHERE iProdImageID in (?)"
 
 func (m *Model) DeleteOtherImages(ctx context.Context, delIDs []int32) error {
@@ -77,7 +104,7 @@ func (m *Model) DeleteOtherImages(ctx context.Context, delIDs []int32) error {
 	return err
 }
 
-func (m *Model) SaveProductImages(formValues map[string][]string) (ProductImgForm, error) {
+func (m *Model) SaveProductImages(ctx context.Context, formValues map[string][]string) (ProductImgForm, error) {
 
 	productImages := ProductImgForm{}
 	if err := decoder.Decode(&productImages, formValues); err != nil {
@@ -95,7 +122,7 @@ func (m *Model) SaveProductImages(formValues map[string][]string) (ProductImgFor
 		newValues["vImage_AltTag"] = productImages.VImage_AltTag
 	}
 
-	tx, err := m.Beginx()
+	tx, err := m.BeginTxx(ctx, nil)
 	if err != nil {
 		return ProductImgForm{}, err
 	}
@@ -110,7 +137,7 @@ func (m *Model) SaveProductImages(formValues map[string][]string) (ProductImgFor
 		mainImgQry := fmt.Sprintf("UPDATE product SET %s WHERE iProdID = :iProdID", strings.Join(newParams, ", "))
 		newValues["iProdID"] = uint(productImages.IProdID)
 
-		_, err = tx.NamedExec(mainImgQry, newValues)
+		_, err = tx.NamedExecContext(ctx, mainImgQry, newValues)
 		if err != nil {
 			return ProductImgForm{}, err
 		}
@@ -122,7 +149,7 @@ func (m *Model) SaveProductImages(formValues map[string][]string) (ProductImgFor
 		qry, args, _ := sqlx.In(delOtherQry, productImages.ToDelete)
 		qry = m.Rebind(qry)
 
-		_, err := m.Exec(qry, args...)
+		_, err := m.ExecContext(ctx, qry, args...)
 		if err != nil {
 			return ProductImgForm{}, err
 		}
@@ -140,7 +167,7 @@ func (m *Model) SaveProductImages(formValues map[string][]string) (ProductImgFor
 		}
 
 		for _, img := range productImages.VAddlImages {
-			_, err := tx.Exec(replaceQry, productImages.IProdID, img, altTag, "A")
+			_, err := tx.ExecContext(ctx, replaceQry, productImages.IProdID, img, altTag, "A")
 			if err != nil {
 				return ProductImgForm{}, err
 			}
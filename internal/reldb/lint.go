@@ -0,0 +1,279 @@
+package reldb
+
+import (
+	"context"
+	"fmt"
+)
+
+// LintFinding is a row flagged by one of the catalog lint queries.
+type LintFinding struct {
+	ID     uint32 `db:"id" json:"id"`
+	Detail string `db:"detail" json:"detail"`
+}
+
+func (m *Model) lintQuery(ctx context.Context, query string) ([]LintFinding, error) {
+
+	findings := []LintFinding{}
+	if err := m.SelectContext(ctx, &findings, query); err != nil {
+		return nil, err
+	}
+
+	return findings, nil
+}
+
+// ProductsInMissingCategories finds products whose iPCatID has no prodcat row.
+func (m *Model) ProductsInMissingCategories(ctx context.Context) ([]LintFinding, error) {
+
+	query := `SELECT
+				p.iProdID id,
+				CONCAT('iPCatID ', p.iPCatID, ' does not exist') detail
+			FROM
+				product p LEFT JOIN
+				prodcat c ON p.iPCatID = c.iPCatID
+			WHERE
+				c.iPCatID IS NULL
+			ORDER BY
+				p.iProdID`
+
+	findings, err := m.lintQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching products in missing categories: %s", err)
+	}
+
+	return findings, nil
+}
+
+// ActiveProductsInInactiveCategories finds active products filed under a
+// category that is not active.
+func (m *Model) ActiveProductsInInactiveCategories(ctx context.Context) ([]LintFinding, error) {
+
+	query := `SELECT
+				p.iProdID id,
+				CONCAT('category ', c.iPCatID, ' (', c.vName, ') has status ', COALESCE(c.cStatus, 'NULL')) detail
+			FROM
+				product p JOIN
+				prodcat c ON p.iPCatID = c.iPCatID
+			WHERE
+				p.cStatus = 'A' AND
+				COALESCE(c.cStatus, 'I') <> 'A'
+			ORDER BY
+				p.iProdID`
+
+	findings, err := m.lintQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching products in inactive categories: %s", err)
+	}
+
+	return findings, nil
+}
+
+// DuplicateProductURLNames finds URL names shared by more than one product.
+// Every product sharing the name is reported.
+func (m *Model) DuplicateProductURLNames(ctx context.Context) ([]LintFinding, error) {
+
+	query := `SELECT
+				p.iProdID id,
+				CONCAT('"', p.vUrlName, '" used by ', d.iCount, ' products') detail
+			FROM
+				product p JOIN
+				(SELECT
+					vUrlName,
+					COUNT(*) iCount
+				FROM
+					product
+				GROUP BY
+					vUrlName
+				HAVING
+					COUNT(*) > 1) d ON p.vUrlName = d.vUrlName
+			ORDER BY
+				p.iProdID`
+
+	findings, err := m.lintQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching duplicate product url names: %s", err)
+	}
+
+	return findings, nil
+}
+
+// DuplicateCategoryURLNames finds URL names shared by more than one
+// category. Every category sharing the name is reported.
+func (m *Model) DuplicateCategoryURLNames(ctx context.Context) ([]LintFinding, error) {
+
+	query := `SELECT
+				c.iPCatID id,
+				CONCAT('"', c.vUrlName, '" used by ', d.iCount, ' categories') detail
+			FROM
+				prodcat c JOIN
+				(SELECT
+					vUrlName,
+					COUNT(*) iCount
+				FROM
+					prodcat
+				GROUP BY
+					vUrlName
+				HAVING
+					COUNT(*) > 1) d ON c.vUrlName = d.vUrlName
+			ORDER BY
+				c.iPCatID`
+
+	findings, err := m.lintQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching duplicate category url names: %s", err)
+	}
+
+	return findings, nil
+}
+
+// ProductsWithEmptyNames finds products without a name or URL name.
+func (m *Model) ProductsWithEmptyNames(ctx context.Context) ([]LintFinding, error) {
+
+	query := `SELECT
+				iProdID id,
+				CASE WHEN TRIM(COALESCE(vName, '')) = '' THEN 'empty vName' ELSE 'empty vUrlName' END detail
+			FROM
+				product
+			WHERE
+				TRIM(COALESCE(vName, '')) = '' OR
+				TRIM(COALESCE(vUrlName, '')) = ''
+			ORDER BY
+				iProdID`
+
+	findings, err := m.lintQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching products with empty names: %s", err)
+	}
+
+	return findings, nil
+}
+
+// CategoriesWithEmptyNames finds categories without a name or URL name.
+func (m *Model) CategoriesWithEmptyNames(ctx context.Context) ([]LintFinding, error) {
+
+	query := `SELECT
+				iPCatID id,
+				CASE WHEN TRIM(COALESCE(vName, '')) = '' THEN 'empty vName' ELSE 'empty vUrlName' END detail
+			FROM
+				prodcat
+			WHERE
+				TRIM(COALESCE(vName, '')) = '' OR
+				TRIM(COALESCE(vUrlName, '')) = ''
+			ORDER BY
+				iPCatID`
+
+	findings, err := m.lintQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching categories with empty names: %s", err)
+	}
+
+	return findings, nil
+}
+
+// InconsistentProductPrices finds products whose original (struck out)
+// price is set but lower than the selling price, or whose prices are
+// negative. A product breaking several rules has a finding for each.
+func (m *Model) InconsistentProductPrices(ctx context.Context) ([]LintFinding, error) {
+
+	query := `SELECT
+				iProdID id,
+				'negative price' detail
+			FROM
+				product
+			WHERE
+				fPrice < 0 OR fOPrice < 0 OR fRetailPrice < 0 OR fRetailOPrice < 0
+			UNION ALL
+			SELECT
+				iProdID id,
+				CONCAT('fOPrice ', fOPrice, ' below fPrice ', fPrice) detail
+			FROM
+				product
+			WHERE
+				fOPrice > 0 AND fOPrice < fPrice
+			UNION ALL
+			SELECT
+				iProdID id,
+				CONCAT('fRetailOPrice ', fRetailOPrice, ' below fRetailPrice ', fRetailPrice) detail
+			FROM
+				product
+			WHERE
+				fRetailOPrice > 0 AND fRetailOPrice < fRetailPrice
+			ORDER BY
+				id`
+
+	findings, err := m.lintQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching inconsistent product prices: %s", err)
+	}
+
+	return findings, nil
+}
+
+// ActiveProductsWithoutPrice finds active products that cannot be sold
+// because they have no selling price.
+func (m *Model) ActiveProductsWithoutPrice(ctx context.Context) ([]LintFinding, error) {
+
+	query := `SELECT
+				iProdID id,
+				'active product with fPrice 0' detail
+			FROM
+				product
+			WHERE
+				cStatus = 'A' AND
+				fPrice = 0 AND
+				fRetailPrice = 0
+			ORDER BY
+				iProdID`
+
+	findings, err := m.lintQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching active products without price: %s", err)
+	}
+
+	return findings, nil
+}
+
+// ProductAttributesWithoutValues finds product attributes with no value.
+// The finding is reported against the iProdAttribID.
+func (m *Model) ProductAttributesWithoutValues(ctx context.Context) ([]LintFinding, error) {
+
+	query := `SELECT
+				pa.iProdAttribID id,
+				CONCAT('product ', pa.iProdID, ' attribute ', COALESCE(a.vName, pa.iAttribID), ' has no value') detail
+			FROM
+				product_attrib pa LEFT JOIN
+				attribute a ON pa.iAttribID = a.iAttribID
+			WHERE
+				TRIM(COALESCE(pa.vValue, '')) = ''
+			ORDER BY
+				pa.iProdAttribID`
+
+	findings, err := m.lintQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching product attributes without values: %s", err)
+	}
+
+	return findings, nil
+}
+
+// CategoryAttributesWithoutValues finds category attributes with no value.
+// The finding is reported against the iAttribDatID.
+func (m *Model) CategoryAttributesWithoutValues(ctx context.Context) ([]LintFinding, error) {
+
+	query := `SELECT
+				pca.iAttribDatID id,
+				CONCAT('category ', pca.iPCatID, ' attribute ', COALESCE(a.vName, pca.iAttribID), ' has no value') detail
+			FROM
+				prodcat_attrib_dat pca LEFT JOIN
+				attribute a ON pca.iAttribID = a.iAttribID
+			WHERE
+				TRIM(COALESCE(pca.vName, '')) = ''
+			ORDER BY
+				pca.iAttribDatID`
+
+	findings, err := m.lintQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching category attributes without values: %s", err)
+	}
+
+	return findings, nil
+}
@@ -10,6 +10,12 @@ import (
 
 type Model struct {
 	*sqlx.DB
+	conn *sqlx.Conn // set on a Model returned by Snapshot
+
+	// SKUOverrides replace generated SKU prices and flags by SKU ID
+	SKUOverrides map[string]SKUOverride
+	// CategoryOrder orders the children in CategoryTree
+	CategoryOrder CategoryOrder
 }
 
 type TableDef struct {
@@ -40,7 +46,7 @@ func NewModel(cfg *Config) (*Model, error) {
 	}
 
 	return &Model{
-		dbHandle,
+		DB: dbHandle,
 	}, nil
 }
 
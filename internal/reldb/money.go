@@ -0,0 +1,142 @@
+package reldb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Money is an amount in minor units (paise, cents), so 12.50 is Money(1250).
+// It scans the DECIMAL and FLOAT price columns and stores them in DynamoDB as
+// exact numbers with two decimals, so prices never pick up binary rounding
+// on the way from MySQL.
+type Money int64
+
+// moneyScale is the number of minor units in a major unit.
+const moneyScale = 100
+
+// ParseMoney parses a decimal amount such as "12.5", "-0.75" or "1e2".
+// Digits beyond the second decimal are rounded half away from zero.
+func ParseMoney(s string) (Money, error) {
+
+	s = strings.TrimSpace(s)
+	if strings.ContainsAny(s, "eE") {
+		// Exponents only come from FLOAT columns, format them out first
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	neg := strings.HasPrefix(s, "-")
+	digits := strings.TrimLeft(s, "+-")
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	for _, part := range []string{whole, frac} {
+		if strings.Trim(part, "0123456789") != "" {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+	}
+
+	round := len(frac) > 2 && frac[2] >= '5'
+	frac = (frac + "00")[:2]
+
+	units, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %s", s, err)
+	}
+	if round {
+		units++
+	}
+	if neg {
+		units = -units
+	}
+
+	return Money(units), nil
+}
+
+// String formats the amount with exactly two decimals.
+func (m Money) String() string {
+
+	sign := ""
+	units := int64(m)
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, units/moneyScale, units%moneyScale)
+}
+
+// Scan implements sql.Scanner. The driver hands DECIMAL columns over as
+// text and FLOAT columns as float64 or text, depending on the protocol.
+func (m *Money) Scan(src any) error {
+
+	var err error
+	switch v := src.(type) {
+	case []byte:
+		*m, err = ParseMoney(string(v))
+	case string:
+		*m, err = ParseMoney(v)
+	case int64:
+		*m = Money(v * moneyScale)
+	case float64:
+		*m, err = ParseMoney(strconv.FormatFloat(v, 'f', -1, 64))
+	case float32:
+		*m, err = ParseMoney(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case nil:
+		return fmt.Errorf("cannot scan NULL into Money, use *Money")
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+
+	return err
+}
+
+// Value implements driver.Valuer.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// MarshalDynamoDBAttributeValue stores the amount as an exact number.
+func (m Money) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return &types.AttributeValueMemberN{Value: m.String()}, nil
+}
+
+func (m *Money) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+
+	n, ok := av.(*types.AttributeValueMemberN)
+	if !ok {
+		return fmt.Errorf("cannot unmarshal %T into Money", av)
+	}
+
+	var err error
+	*m, err = ParseMoney(n.Value)
+
+	return err
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimals.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts the amount as a JSON number or string.
+func (m *Money) UnmarshalJSON(data []byte) error {
+
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+
+	var err error
+	*m, err = ParseMoney(s)
+
+	return err
+}
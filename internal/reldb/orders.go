@@ -1,6 +1,7 @@
 package reldb
 
 import (
+	"context"
 	"time"
 )
 
@@ -9,7 +10,7 @@ type OrderSummary struct {
 	DtDt           time.Time `db:"dtDt" json:"dtDt,omitempty"`
 	VRecpName      *string   `db:"vRecpName" json:"vRecpName,omitempty"`
 	VRecpCountry   *string   `db:"vRecpCountry" json:"vRecpCountry,omitempty"`
-	FTotal         float32   `db:"fTotal" json:"fTotal,omitempty"`
+	FTotal         Money     `db:"fTotal" json:"fTotal,omitempty"`
 	CPaymentStatus *string   `db:"cPaymentStatus" json:"cPaymentStatus,omitempty"`
 	CStatus        *string   `db:"cStatus" json:"cStatus,omitempty"`
 	VSource        *string   `db:"vSource" json:"vSource,omitempty"`
@@ -41,10 +42,10 @@ type OrderBilling struct {
 }
 
 type OrderPayment struct {
-	FTotalRate       float32 `db:"fTotalRate" json:"fTotalRate,omitempty"`
-	FTotalDiscount   float32 `db:"fTotalDiscount" json:"fTotalDiscount,omitempty"`
-	FShipping        float32 `db:"fShipping" json:"fShipping,omitempty"`
-	FTotal           float32 `db:"fTotal" json:"fTotal,omitempty"`
+	FTotalRate       Money   `db:"fTotalRate" json:"fTotalRate,omitempty"`
+	FTotalDiscount   Money   `db:"fTotalDiscount" json:"fTotalDiscount,omitempty"`
+	FShipping        Money   `db:"fShipping" json:"fShipping,omitempty"`
+	FTotal           Money   `db:"fTotal" json:"fTotal,omitempty"`
 	VInstructions    *string `db:"vInstructions" json:"vInstructions,omitempty"`
 	VRemarks         *string `db:"vRemarks" json:"vRemarks,omitempty"`
 	CPaymentType     *string `db:"cPaymentType" json:"cPaymentType,omitempty"`
@@ -54,8 +55,8 @@ type OrderPayment struct {
 	CPaymentStatus   *string `db:"cPaymentStatus" json:"cPaymentStatus,omitempty"`
 	CStatus          *string `db:"cStatus" json:"cStatus,omitempty"`
 	IDiscCoupID      int     `db:"iDiscCoupID" json:"iDiscCoupID,omitempty"`
-	FCouponDisc      *string `db:"fCouponDisc,omitempty" json:"fCouponDisc,omitempty"`
-	FGiftWrapCharges float32 `db:"fGiftWrapCharges" json:"fGiftWrapCharges,omitempty"`
+	FCouponDisc      *Money  `db:"fCouponDisc,omitempty" json:"fCouponDisc,omitempty"`
+	FGiftWrapCharges Money   `db:"fGiftWrapCharges" json:"fGiftWrapCharges,omitempty"`
 }
 
 type OrderGiftOptions struct {
@@ -82,8 +83,8 @@ type OrderProduct struct {
 	VAttributeValue *string `db:"vAttributeValue" json:"vAttributeValue,omitempty"`
 	VColorValue     *string `db:"vColorValue" json:"vColorValue,omitempty"`
 	IQty            int     `db:"iQty" json:"iQty,omitempty"`
-	FRate           float32 `db:"fRate" json:"fRate,omitempty"`
-	FPayable        float32 `db:"fPayable" json:"fPayable,omitempty"`
+	FRate           Money   `db:"fRate" json:"fRate,omitempty"`
+	FPayable        Money   `db:"fPayable" json:"fPayable,omitempty"`
 	CGiftWrap       *string `db:"cGiftWrap" json:"cGiftWrap,omitempty"`
 }
 
@@ -97,19 +98,19 @@ type OrderShipment struct {
 	DtUpdatedAt  time.Time `db:"dtUpdatedAt" json:"dtUpdatedAt,omitempty"`
 }
 
-func (m *Model) OrderCount() (int, error) {
+func (m *Model) OrderCount(ctx context.Context) (int, error) {
 
 	var count int
 
 	query := "SELECT COUNT(*) FROM orders"
-	if err := m.Get(&count, query); err != nil {
+	if err := m.GetContext(ctx, &count, query); err != nil {
 		return 0, err
 	}
 
 	return count, nil
 }
 
-func (m *Model) Orders(offset, limit uint) ([]OrderSummary, error) {
+func (m *Model) Orders(ctx context.Context, offset, limit uint) ([]OrderSummary, error) {
 
 	query := `SELECT
 				iOrdID,
@@ -126,14 +127,14 @@ func (m *Model) Orders(offset, limit uint) ([]OrderSummary, error) {
 			LIMIT ?, ?`
 
 	orders := []OrderSummary{}
-	if err := m.Select(&orders, query, offset, limit); err != nil {
+	if err := m.SelectContext(ctx, &orders, query, offset, limit); err != nil {
 		return nil, err
 	}
 
 	return orders, nil
 }
 
-func (m *Model) OrderDetail(iOrdID uint) (Order, error) {
+func (m *Model) OrderDetail(ctx context.Context, iOrdID uint) (Order, error) {
 
 	query := `SELECT
 				iOrdID,
@@ -180,7 +181,7 @@ func (m *Model) OrderDetail(iOrdID uint) (Order, error) {
 				LEFT JOIN giftwrap_cards gwc ON o.iGWCardID = gwc.iGWCardID
 			WHERE iOrdID = ?`
 
-	row := m.QueryRowx(query, iOrdID)
+	row := m.QueryRowxContext(ctx, query, iOrdID)
 	order := Order{}
 	if err := row.StructScan(&order); err != nil {
 		return Order{}, err
@@ -189,7 +190,7 @@ func (m *Model) OrderDetail(iOrdID uint) (Order, error) {
 	return order, nil
 }
 
-func (m *Model) OrderProducts(iOrdID uint) ([]OrderProduct, error) {
+func (m *Model) OrderProducts(ctx context.Context, iOrdID uint) ([]OrderProduct, error) {
 
 	query := `SELECT
 				p.iProdID,
@@ -214,14 +215,14 @@ func (m *Model) OrderProducts(iOrdID uint) ([]OrderProduct, error) {
 			WHERE o.iOrdID = ?`
 
 	products := []OrderProduct{}
-	if err := m.Select(&products, query, iOrdID); err != nil {
+	if err := m.SelectContext(ctx, &products, query, iOrdID); err != nil {
 		return nil, err
 	}
 
 	return products, nil
 }
 
-func (m *Model) OrderShipments(iOrdID uint) ([]OrderShipment, error) {
+func (m *Model) OrderShipments(ctx context.Context, iOrdID uint) ([]OrderShipment, error) {
 
 	query := `SELECT 
 				iOrdShipID,
@@ -236,7 +237,7 @@ func (m *Model) OrderShipments(iOrdID uint) ([]OrderShipment, error) {
 			WHERE iOrdID = ?`
 
 	shipments := []OrderShipment{}
-	if err := m.Select(&shipments, query, iOrdID); err != nil {
+	if err := m.SelectContext(ctx, &shipments, query, iOrdID); err != nil {
 		return nil, err
 	}
 
@@ -254,9 +255,9 @@ var updtQuery = `UPDATE order_shipment SET
 				vShipCode = ?
 			WHERE iOrdShipID = ?`
 
-func (m *Model) SetOrderShipments(shipments []OrderShipment) ([]OrderShipment, error) {
+func (m *Model) SetOrderShipments(ctx context.Context, shipments []OrderShipment) ([]OrderShipment, error) {
 
-	tx, err := m.Beginx()
+	tx, err := m.BeginTxx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -264,7 +265,7 @@ func (m *Model) SetOrderShipments(shipments []OrderShipment) ([]OrderShipment, e
 	for _, shipment := range shipments {
 
 		if shipment.IOrdShipID == 0 {
-			result, err := tx.Exec(insQuery,
+			result, err := tx.ExecContext(ctx, insQuery,
 				shipment.IOrdID,
 				shipment.ICourierID,
 				shipment.VShipCode,
@@ -279,7 +280,7 @@ func (m *Model) SetOrderShipments(shipments []OrderShipment) ([]OrderShipment, e
 			}
 			shipment.IOrdShipID = uint32(iOrdShipId)
 		} else {
-			result, err := tx.Exec(updtQuery,
+			result, err := tx.ExecContext(ctx, updtQuery,
 				shipment.IOrdID,
 				shipment.ICourierID,
 				shipment.VShipCode,
@@ -303,10 +304,10 @@ func (m *Model) SetOrderShipments(shipments []OrderShipment) ([]OrderShipment, e
 	return shipments, nil
 }
 
-func (m *Model) DeleteShipment(iOrdShipID uint) (int, error) {
+func (m *Model) DeleteShipment(ctx context.Context, iOrdShipID uint) (int, error) {
 
 	delQuery := `DELETE FROM order_shipment WHERE iOrdShipID = ?`
-	result, err := m.Exec(delQuery, iOrdShipID)
+	result, err := m.ExecContext(ctx, delQuery, iOrdShipID)
 	if err != nil {
 		return 0, err
 	}
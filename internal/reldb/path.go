@@ -0,0 +1,69 @@
+package reldb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Crumb is one category of a breadcrumb.
+type Crumb struct {
+	IPCatID  uint32
+	VName    string
+	VURLName string
+}
+
+// categoryPath returns the breadcrumb of a category, from its top level
+// ancestor down to the category itself. The synthetic root is left out.
+func categoryPath(catSummMap map[uint32]*CategorySummary, iPCatID uint32) []Crumb {
+
+	ids := ancestry(catSummMap, iPCatID)
+	path := make([]Crumb, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		if ids[i] == 0 {
+			continue
+		}
+		category := catSummMap[ids[i]]
+		path = append(path, Crumb{category.IPCatID, category.VName, category.VURLName})
+	}
+
+	return path
+}
+
+// CategoryPaths returns the breadcrumb of every category by iPCatID.
+func (m *Model) CategoryPaths(ctx context.Context) (map[uint32][]Crumb, error) {
+
+	catSummMap, err := m.CategoryMaster(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching category summary: %s", err)
+	}
+
+	paths := make(map[uint32][]Crumb, len(catSummMap))
+	for iPCatID := range catSummMap {
+		paths[iPCatID] = categoryPath(catSummMap, iPCatID)
+	}
+
+	return paths, nil
+}
+
+// PathString joins the names of a breadcrumb with sep.
+func PathString(path []Crumb, sep string) string {
+
+	names := make([]string, len(path))
+	for i, crumb := range path {
+		names[i] = crumb.VName
+	}
+
+	return strings.Join(names, sep)
+}
+
+// PathURL joins the URL names of a breadcrumb into a URL path.
+func PathURL(path []Crumb) string {
+
+	names := make([]string, len(path))
+	for i, crumb := range path {
+		names[i] = crumb.VURLName
+	}
+
+	return "/" + strings.Join(names, "/")
+}
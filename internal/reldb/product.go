@@ -1,15 +1,16 @@
 package reldb
 
 import (
+	"context"
 	"fmt"
 )
 
 type ProdPrice struct {
-	FRetailPrice      float64 `db:"fRetailPrice" json:"fRetailPrice"`
-	FRetailOPrice     float64 `db:"fRetailOPrice" json:"fRetailOPrice"`
-	FShipping         float64 `db:"fShipping" json:"fShipping"`
-	FPrice            float64 `db:"fPrice" json:"fPrice"`
-	FOPrice           float64 `db:"fOPrice" json:"fOPrice"`
+	FRetailPrice      Money   `db:"fRetailPrice" json:"fRetailPrice"`
+	FRetailOPrice     Money   `db:"fRetailOPrice" json:"fRetailOPrice"`
+	FShipping         Money   `db:"fShipping" json:"fShipping"`
+	FPrice            Money   `db:"fPrice" json:"fPrice"`
+	FOPrice           Money   `db:"fOPrice" json:"fOPrice"`
 	FActualWeight     float64 `db:"fActualWeight" json:"fActualWeight"`
 	FVolumetricWeight float64 `db:"fVolumetricWeight" json:"fVolumetricWeight"`
 }
@@ -21,6 +22,23 @@ type Images struct {
 	VImageAltTag      *string `db:"vImage_AltTag" json:"vImage_AltTag"`
 }
 
+// ImageMeta describes the file of an image. Width, height and format are
+// left out for formats the image decoders do not know.
+type ImageMeta struct {
+	IWidth  int    `json:"iWidth,omitempty" dynamodbav:",omitempty"`
+	IHeight int    `json:"iHeight,omitempty" dynamodbav:",omitempty"`
+	VFormat string `json:"vFormat,omitempty"`
+	IBytes  int64  `json:"iBytes"`
+	VSHA256 string `json:"vSHA256"`
+}
+
+// ImagesMeta holds the ImageMeta of the images of Images whose file was
+// found.
+type ImagesMeta struct {
+	VSmallImage *ImageMeta `json:"vSmallImage,omitempty"`
+	VImage      *ImageMeta `json:"vImage,omitempty"`
+}
+
 type Product struct {
 	IProdID          uint32  `db:"iProdID" json:"iProdID"`
 	IPCatID          uint32  `db:"iPCatID" json:"iPCatID"`
@@ -33,10 +51,12 @@ type Product struct {
 	VDescription     *string `db:"vDescription" json:"vDescription"`
 	ProdPrice
 	Images
+	ImagesMeta *ImagesMeta        `db:"-" json:"imagesMeta,omitempty"`
 	CStatus    *string            `db:"cStatus" json:"cStatus"`
 	VYTID      *string            `db:"vYTID" json:"vYTID"`
 	Attributes []ProductAttribute `db:"-" json:"attributes"`
 	SKUs       []SKU              `db:"-" json:"skus"`
+	Path       []Crumb            `db:"-" json:"path"`
 }
 
 type Color struct {
@@ -54,10 +74,10 @@ type ProductAttribute struct {
 	VAttribName   *string `db:"vAttribName" json:"vAttribName" diff:"-"`
 	VValue        *string `db:"vValue" json:"vValue" diff:"vValue"`
 	IPCID         uint32  `db:"iAttribPCID" json:"iAttribPCID" diff:"iPCID"`
-	FRetailPrice  float64 `db:"fRetailPrice" json:"fRetailPrice" diff:"fRetailPrice"`
-	FRetailOPrice float64 `db:"fRetailOPrice" json:"fRetailOPrice" diff:"fRetailOPrice"`
-	FPrice        float64 `db:"fPrice" json:"fPrice" diff:"fPrice"`
-	FOPrice       float64 `db:"fOPrice" json:"fOPrice" diff:"fOPrice"`
+	FRetailPrice  Money   `db:"fRetailPrice" json:"fRetailPrice" diff:"fRetailPrice"`
+	FRetailOPrice Money   `db:"fRetailOPrice" json:"fRetailOPrice" diff:"fRetailOPrice"`
+	FPrice        Money   `db:"fPrice" json:"fPrice" diff:"fPrice"`
+	FOPrice       Money   `db:"fOPrice" json:"fOPrice" diff:"fOPrice"`
 	CDefault      *string `db:"cDefault" json:"cDefault" diff:"cDefault"`
 	CStock        *string `db:"cStock" json:"cStock" diff:"cStock"`
 }
@@ -67,10 +87,10 @@ type ProductColor struct {
 	IProdID            uint32  `json:"iColorProdID" db:"iColorProdID"`
 	IColorID           uint32  `json:"iColorID" db:"iColorID"`
 	VColorName         *string `json:"vColorName" db:"vColorName" diff:"-"`
-	FColorRetailPrice  float64 `json:"fColorRetailPrice" db:"fColorRetailPrice"`
-	FColorRetailOPrice float64 `json:"fColorRetailOPrice" db:"fColorRetailOPrice"`
-	FColorPrice        float64 `json:"fColorPrice" db:"fColorPrice"`
-	FColorOPrice       float64 `json:"fColorOPrice" db:"fColorOPrice"`
+	FColorRetailPrice  Money   `json:"fColorRetailPrice" db:"fColorRetailPrice"`
+	FColorRetailOPrice Money   `json:"fColorRetailOPrice" db:"fColorRetailOPrice"`
+	FColorPrice        Money   `json:"fColorPrice" db:"fColorPrice"`
+	FColorOPrice       Money   `json:"fColorOPrice" db:"fColorOPrice"`
 	CColorDefault      *string `json:"cColorDefault" db:"cColorDefault"`
 	CStatus            *string `json:"cStatus" db:"cStatus"`
 }
@@ -85,7 +105,7 @@ type dbPCARow struct {
 	ProductAttribute
 }
 
-func (m *Model) Products() ([]Product, error) {
+func (m *Model) Products(ctx context.Context) ([]Product, error) {
 
 	qry := `SELECT
 				p.iProdID,
@@ -114,14 +134,14 @@ func (m *Model) Products() ([]Product, error) {
 				JOIN prodcat c ON p.iPCatID = c.iPCatID`
 
 	pp := []Product{}
-	if err := m.Select(&pp, qry); err != nil {
+	if err := m.SelectContext(ctx, &pp, qry); err != nil {
 		return nil, fmt.Errorf("error fetching products: %s", err)
 	}
 
 	return pp, nil
 }
 
-func (m *Model) ProductAttributes(iProdID uint32, priced bool) ([]ProductAttribute, error) {
+func (m *Model) ProductAttributes(ctx context.Context, iProdID uint32, priced bool) ([]ProductAttribute, error) {
 
 	if iProdID == 0 {
 		return nil, nil
@@ -179,14 +199,14 @@ func (m *Model) ProductAttributes(iProdID uint32, priced bool) ([]ProductAttribu
 				pa.iPCID NOT IN (SELECT iPCID FROM product_attrib where iProdID = ?)` +
 		addlCQry
 
-	if err := m.Select(&productAttribs, query, iProdID, iProdID, iProdID); err != nil {
+	if err := m.SelectContext(ctx, &productAttribs, query, iProdID, iProdID, iProdID); err != nil {
 		return nil, fmt.Errorf("error fetching product attributes for product %d: %s", iProdID, err)
 	}
 
 	return productAttribs, nil
 }
 
-func (m *Model) ProductColorAttributes(iProdID uint32, priced bool) ([]ProductColorAttribute, error) {
+func (m *Model) ProductColorAttributes(ctx context.Context, iProdID uint32, priced bool) ([]ProductColorAttribute, error) {
 
 	if iProdID == 0 {
 		return nil, nil
@@ -231,14 +251,15 @@ func (m *Model) ProductColorAttributes(iProdID uint32, priced bool) ([]ProductCo
 			WHERE
 				pc.iProdID = ?` + addlQry +
 		`ORDER BY
-				pc.iColorID,
+				c.iRank,
+				pc.iPCID,
 				pa.iProdAttribID`
 
 	pcRows := []dbPCARow{}
 
 	// Tell and exit if no rows found
 	cas := []ProductColorAttribute{}
-	if err := m.Select(&pcRows, query, iProdID); err != nil {
+	if err := m.SelectContext(ctx, &pcRows, query, iProdID); err != nil {
 		return cas, fmt.Errorf("error scanning rows: %w", err)
 	}
 
@@ -0,0 +1,111 @@
+package reldb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Row is a single result row of an arbitrary query keyed by column name.
+// Values are inferred from the column type: nil for NULL, json.Number for
+// numeric columns (so decimals stay exact), time.Time for temporal columns,
+// []byte for binary columns and string for everything else.
+type Row map[string]any
+
+// QueryFile reads the SQL in fileName and runs it with QueryRows.
+func (m *Model) QueryFile(ctx context.Context, fileName string, params map[string]any) ([]string, []Row, error) {
+
+	qryBytes, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading query file %s: %w", fileName, err)
+	}
+
+	return m.QueryRows(ctx, string(qryBytes), params)
+}
+
+// QueryRows runs an arbitrary query and returns the column names in select
+// order along with the rows. If params is not empty the query is treated as
+// a named query and :name placeholders are bound from params.
+func (m *Model) QueryRows(ctx context.Context, query string, params map[string]any) ([]string, []Row, error) {
+
+	var rows *sqlx.Rows
+	var err error
+	if len(params) > 0 {
+		rows, err = m.NamedQueryContext(ctx, query, params)
+	} else {
+		rows, err = m.QueryxContext(ctx, query)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error running query: %w", err)
+	}
+	defer rows.Close()
+
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching column types: %w", err)
+	}
+
+	columns := make([]string, len(colTypes))
+	for i, colType := range colTypes {
+		columns[i] = colType.Name()
+	}
+
+	result := []Row{}
+	for rows.Next() {
+		values, err := rows.SliceScan()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error scanning row: %w", err)
+		}
+
+		row := make(Row, len(columns))
+		for i, value := range values {
+			row[columns[i]] = inferValue(colTypes[i].DatabaseTypeName(), value)
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return columns, result, nil
+}
+
+func inferValue(dbType string, value any) any {
+
+	if value == nil {
+		return nil
+	}
+
+	dbType = strings.TrimPrefix(dbType, "UNSIGNED ")
+	switch dbType {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR",
+		"DECIMAL", "FLOAT", "DOUBLE":
+		return json.Number(fmt.Sprint(asString(value)))
+	case "BINARY", "VARBINARY", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BIT":
+		if b, ok := value.([]byte); ok {
+			return b
+		}
+	}
+
+	return asString(value)
+}
+
+// asString converts a scanned driver value to its string form, leaving
+// non textual values (time.Time) untouched.
+func asString(value any) any {
+
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case int64, float64, float32, uint64, bool:
+		return fmt.Sprint(v)
+	}
+
+	return value
+}
@@ -1,6 +1,9 @@
 package reldb
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 type Recipient struct {
 	PK     string `db:"-"`
@@ -11,7 +14,7 @@ type Recipient struct {
 	Status string `db:"status"`
 }
 
-func (m *Model) Recipients() ([]Recipient, error) {
+func (m *Model) Recipients(ctx context.Context) ([]Recipient, error) {
 
 	qry := `select 
 		s.vRecipientSetName sset,
@@ -24,7 +27,7 @@ func (m *Model) Recipients() ([]Recipient, error) {
 	order by 1, 4`
 
 	rcpts := []Recipient{}
-	if err := m.Select(&rcpts, qry); err != nil {
+	if err := m.SelectContext(ctx, &rcpts, qry); err != nil {
 		return rcpts, fmt.Errorf("error fetching recipients: %w", err)
 	}
 
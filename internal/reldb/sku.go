@@ -1,70 +1,325 @@
 package reldb
 
-import "fmt"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+)
 
 type SKUAttrib struct {
 	VAttribName  string
 	VAttribValue string
 }
+
+// SKU is a variant of a product that can be ordered. VSKUID is stable
+// across runs, see SKUID. Color is set for SKUs that come in a color, with
+// the swatch and rank storefronts need to render it.
 type SKU struct {
+	VSKUID        string
+	IProdID       uint32
 	Attributes    []SKUAttrib
-	FRetailPrice  float64
-	FRetailOPrice float64
-	FPrice        float64
-	FOPrice       float64
+	Color         *Color
+	FRetailPrice  Money
+	FRetailOPrice Money
+	FPrice        Money
+	FOPrice       Money
 	CDefault      string
 	CStock        string
 }
 
-func (m *Model) ProductSKUs(iProdID uint32) ([]SKU, error) {
+// StringOr returns *s, or def when s is nil. Use it instead of
+// dereferencing nullable columns directly.
+func StringOr(s *string, def string) string {
 
-	attribs, err := m.ProductAttributes(iProdID, true)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching attributes for product %d: %s", iProdID, err)
+	if s == nil {
+		return def
 	}
 
-	colorAttribs, err := m.ProductColorAttributes(iProdID, true)
+	return *s
+}
+
+// ColorAttribID is the iAttribID ProductAttributes gives to colors that
+// have no attributes of their own.
+const ColorAttribID = 18
+
+// Variant is one choice of a variant dimension, e.g. Size XL or Color Red.
+// ID is A<iProdAttribID> for attributes and C<iPCID> for colors, which
+// also carry their Color.
+type Variant struct {
+	Dimension     string
+	ID            string
+	Value         string
+	Color         *Color
+	FRetailPrice  Money
+	FRetailOPrice Money
+	FPrice        Money
+	FOPrice       Money
+	Default       bool
+	Stock         bool
+}
+
+// SKUOverride replaces the generated prices, default or stock flag of one
+// SKU. Unset fields keep the generated value.
+type SKUOverride struct {
+	FRetailPrice  *Money  `json:"fRetailPrice"`
+	FRetailOPrice *Money  `json:"fRetailOPrice"`
+	FPrice        *Money  `json:"fPrice"`
+	FOPrice       *Money  `json:"fOPrice"`
+	CDefault      *string `json:"cDefault"`
+	CStock        *string `json:"cStock"`
+}
+
+// ReadSKUOverrides reads a JSON object of SKUOverride keyed by SKU ID.
+func ReadSKUOverrides(fileName string) (map[string]SKUOverride, error) {
+
+	jsonBytes, err := os.ReadFile(fileName)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching color attributes for product %d: %s", iProdID, err)
+		return nil, fmt.Errorf("error reading sku overrides %s: %s", fileName, err)
 	}
 
-	skus := []SKU{}
-	for _, attrib := range attribs {
+	overrides := map[string]SKUOverride{}
+	if err := json.Unmarshal(jsonBytes, &overrides); err != nil {
+		return nil, fmt.Errorf("error decoding sku overrides %s: %s", fileName, err)
+	}
+
+	return overrides, nil
+}
+
+// SKUID identifies the SKU of product iProdID made of variants. It only
+// depends on the source row IDs, so it does not change between runs, e.g.
+// P12-C3-A40 for color 3 with attribute 40.
+func SKUID(iProdID uint32, variants []Variant) string {
 
-		a := SKUAttrib{*attrib.VAttribName, *attrib.VValue}
-		skus = append(skus, SKU{
-			Attributes:    []SKUAttrib{a},
-			FRetailPrice:  attrib.FRetailPrice,
-			FRetailOPrice: attrib.FRetailOPrice,
-			FPrice:        attrib.FPrice,
-			FOPrice:       attrib.FOPrice,
-			CDefault:      *attrib.CDefault,
-			CStock:        *attrib.CStock,
-		})
+	id := fmt.Sprintf("P%d", iProdID)
+	for _, v := range variants {
+		id += "-" + v.ID
 	}
 
-	for _, cattrib := range colorAttribs {
-		skuAttrib := SKUAttrib{"Color", *cattrib.VColorName}
-		for _, attrib := range cattrib.ProductAttributes {
-			d := "N"
-			if *cattrib.CColorDefault == "Y" && *attrib.CDefault == "Y" {
-				d = "Y"
+	return id
+}
+
+// GenerateSKUs returns one SKU for every combination of one variant from
+// each dimension, in dimension order. Dimensions without variants are
+// skipped. A SKU takes all four prices from the last of its variants, in
+// dimension order, with a nonzero FRetailPrice. Prices are not added up:
+// Size M at 200 with Material Steel at 500 makes a SKU at 500, and the
+// size price is dropped. A SKU is a default if all its variants are and in
+// stock if all its variants are. Overrides are applied last.
+func GenerateSKUs(iProdID uint32, dimensions [][]Variant, overrides map[string]SKUOverride) []SKU {
+
+	dimensions = slices.DeleteFunc(slices.Clone(dimensions), func(dimension []Variant) bool { return len(dimension) == 0 })
+	if len(dimensions) == 0 {
+		return nil
+	}
+
+	combinations := [][]Variant{{}}
+	for _, dimension := range dimensions {
+		next := make([][]Variant, 0, len(combinations)*len(dimension))
+		for _, combination := range combinations {
+			for _, v := range dimension {
+				next = append(next, append(combination[:len(combination):len(combination)], v))
+			}
+		}
+		combinations = next
+	}
+
+	skus := make([]SKU, 0, len(combinations))
+	for _, combination := range combinations {
+		sku := SKU{
+			VSKUID:   SKUID(iProdID, combination),
+			IProdID:  iProdID,
+			CDefault: "Y",
+			CStock:   "Y",
+		}
+		for _, v := range combination {
+			sku.Attributes = append(sku.Attributes, SKUAttrib{v.Dimension, v.Value})
+			if v.Color != nil {
+				sku.Color = v.Color
+			}
+			if v.FRetailPrice != 0 {
+				sku.FRetailPrice, sku.FRetailOPrice, sku.FPrice, sku.FOPrice = v.FRetailPrice, v.FRetailOPrice, v.FPrice, v.FOPrice
+			}
+			if !v.Default {
+				sku.CDefault = "N"
 			}
-			s := "N"
-			if *cattrib.CStatus == "A" && *attrib.CStock == "Y" {
-				s = "Y"
+			if !v.Stock {
+				sku.CStock = "N"
 			}
-			skus = append(skus, SKU{
-				Attributes:    append([]SKUAttrib{skuAttrib}, SKUAttrib{*attrib.VAttribName, *attrib.VValue}),
-				FRetailPrice:  attrib.FRetailPrice,
-				FRetailOPrice: attrib.FRetailOPrice,
-				FPrice:        attrib.FPrice,
-				FOPrice:       attrib.FOPrice,
-				CDefault:      d,
-				CStock:        s,
-			})
 		}
+		skus = append(skus, applySKUOverride(sku, overrides[sku.VSKUID]))
+	}
+
+	return skus
+}
+
+func applySKUOverride(sku SKU, o SKUOverride) SKU {
+
+	if o.FRetailPrice != nil {
+		sku.FRetailPrice = *o.FRetailPrice
+	}
+	if o.FRetailOPrice != nil {
+		sku.FRetailOPrice = *o.FRetailOPrice
+	}
+	if o.FPrice != nil {
+		sku.FPrice = *o.FPrice
+	}
+	if o.FOPrice != nil {
+		sku.FOPrice = *o.FOPrice
+	}
+	if o.CDefault != nil {
+		sku.CDefault = *o.CDefault
+	}
+	if o.CStock != nil {
+		sku.CStock = *o.CStock
+	}
+
+	return sku
+}
+
+// chooseDefault leaves exactly one default SKU: the first one marked
+// default, else the first one in stock, else the first one.
+func chooseDefault(skus []SKU) {
+
+	if len(skus) == 0 {
+		return
+	}
+
+	chosen := slices.IndexFunc(skus, func(sku SKU) bool { return sku.CDefault == "Y" })
+	if chosen < 0 {
+		chosen = max(slices.IndexFunc(skus, func(sku SKU) bool { return sku.CStock == "Y" }), 0)
+	}
+	for i := range skus {
+		skus[i].CDefault = "N"
+	}
+	skus[chosen].CDefault = "Y"
+}
+
+// attributeVariant is the variant of a priced product attribute. colors
+// are the colors of the product by iPCID.
+func attributeVariant(attrib ProductAttribute, colors map[uint32]Color) Variant {
+
+	v := Variant{
+		Dimension:     StringOr(attrib.VAttribName, ""),
+		ID:            fmt.Sprintf("A%d", attrib.IProdAttribID),
+		Value:         StringOr(attrib.VValue, ""),
+		FRetailPrice:  attrib.FRetailPrice,
+		FRetailOPrice: attrib.FRetailOPrice,
+		FPrice:        attrib.FPrice,
+		FOPrice:       attrib.FOPrice,
+		Default:       StringOr(attrib.CDefault, "N") == "Y",
+		Stock:         StringOr(attrib.CStock, "N") == "Y",
+	}
+
+	// Colors without attributes come with their iPCID and status
+	if attrib.IAttribID == ColorAttribID {
+		v.ID = fmt.Sprintf("C%d", attrib.IProdAttribID)
+		v.Stock = StringOr(attrib.CStock, "I") == "A"
+		if color, ok := colors[attrib.IProdAttribID]; ok {
+			v.Color = &color
+		}
+	}
+
+	return v
+}
+
+// colorVariant is the variant of a product color.
+func colorVariant(color ProductColor, colors map[uint32]Color) Variant {
+
+	v := Variant{
+		Dimension:     "Color",
+		ID:            fmt.Sprintf("C%d", color.IPCID),
+		Value:         StringOr(color.VColorName, ""),
+		FRetailPrice:  color.FColorRetailPrice,
+		FRetailOPrice: color.FColorRetailOPrice,
+		FPrice:        color.FColorPrice,
+		FOPrice:       color.FColorOPrice,
+		Default:       StringOr(color.CColorDefault, "N") == "Y",
+		Stock:         StringOr(color.CStatus, "I") == "A",
+	}
+	if c, ok := colors[color.IPCID]; ok {
+		v.Color = &c
+	}
+
+	return v
+}
+
+// variantDimensions groups attributes into one dimension per iAttribID,
+// colors first and then by iAttribID. Colors are ordered by rank, other
+// values keep their order.
+func variantDimensions(attribs []ProductAttribute, colors map[uint32]Color) [][]Variant {
+
+	ids := []uint32{}
+	byID := map[uint32][]Variant{}
+	for _, attrib := range attribs {
+		if _, ok := byID[attrib.IAttribID]; !ok {
+			ids = append(ids, attrib.IAttribID)
+		}
+		byID[attrib.IAttribID] = append(byID[attrib.IAttribID], attributeVariant(attrib, colors))
+	}
+	sort.SliceStable(byID[ColorAttribID], func(i, j int) bool {
+		return colorRank(byID[ColorAttribID][i]) < colorRank(byID[ColorAttribID][j])
+	})
+	sort.Slice(ids, func(i, j int) bool {
+		if (ids[i] == ColorAttribID) != (ids[j] == ColorAttribID) {
+			return ids[i] == ColorAttribID
+		}
+		return ids[i] < ids[j]
+	})
+
+	dimensions := make([][]Variant, 0, len(ids))
+	for _, id := range ids {
+		dimensions = append(dimensions, byID[id])
+	}
+
+	return dimensions
+}
+
+func colorRank(v Variant) int64 {
+
+	if v.Color == nil {
+		return 0
+	}
+
+	return v.Color.IRank
+}
+
+// ProductSKUs generates the SKUs of a product: the combinations of its
+// priced attributes, and for every color with attributes of its own the
+// combinations of the color with those attributes. Overrides come from
+// m.SKUOverrides, and exactly one SKU is the default.
+func (m *Model) ProductSKUs(ctx context.Context, iProdID uint32) ([]SKU, error) {
+
+	attribs, err := m.ProductAttributes(ctx, iProdID, true)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching attributes for product %d: %s", iProdID, err)
+	}
+
+	colorAttribs, err := m.ProductColorAttributes(ctx, iProdID, true)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching color attributes for product %d: %s", iProdID, err)
+	}
+
+	colors, err := m.ProductColors(ctx, iProdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return productSKUs(iProdID, attribs, colorAttribs, colors, m.SKUOverrides), nil
+}
+
+// productSKUs generates the SKUs of a product from its fetched rows, see
+// ProductSKUs.
+func productSKUs(iProdID uint32, attribs []ProductAttribute, colorAttribs []ProductColorAttribute, colors map[uint32]Color, overrides map[string]SKUOverride) []SKU {
+
+	skus := GenerateSKUs(iProdID, variantDimensions(attribs, colors), overrides)
+	for _, cattrib := range colorAttribs {
+		dimensions := append([][]Variant{{colorVariant(cattrib.ProductColor, colors)}}, variantDimensions(cattrib.ProductAttributes, colors)...)
+		skus = append(skus, GenerateSKUs(iProdID, dimensions, overrides)...)
 	}
+	chooseDefault(skus)
 
-	return skus, nil
+	return skus
 }
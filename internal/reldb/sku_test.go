@@ -0,0 +1,215 @@
+package reldb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func variant(dimension, id, value string, price Money, def, stock bool) Variant {
+	return Variant{
+		Dimension:    dimension,
+		ID:           id,
+		Value:        value,
+		FRetailPrice: price,
+		FPrice:       price * 9 / 10,
+		Default:      def,
+		Stock:        stock,
+	}
+}
+
+func priced(sku SKU, price Money) SKU {
+
+	sku.FRetailPrice, sku.FPrice = price, price*9/10
+
+	return sku
+}
+
+var (
+	sizeS = variant("Size", "A1", "S", 0, true, true)
+	sizeM = variant("Size", "A2", "M", 20000, false, true)
+	wood  = variant("Material", "A3", "Wood", 0, true, true)
+	steel = variant("Material", "A4", "Steel", 50000, true, false)
+	matte = variant("Finish", "A5", "Matte", 0, true, true)
+)
+
+func TestGenerateSKUs(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		dimensions [][]Variant
+		overrides  map[string]SKUOverride
+		want       []SKU
+	}{
+		{
+			name: "no dimensions",
+		},
+		{
+			name:       "only empty dimensions",
+			dimensions: [][]Variant{{}, nil},
+		},
+		{
+			name:       "single variant",
+			dimensions: [][]Variant{{sizeM}},
+			want: []SKU{
+				priced(SKU{VSKUID: "P7-A2", IProdID: 7, Attributes: []SKUAttrib{{"Size", "M"}}, CDefault: "N", CStock: "Y"}, 20000),
+			},
+		},
+		{
+			// The last priced variant wins, the size price is dropped
+			name:       "three dimensions",
+			dimensions: [][]Variant{{sizeS, sizeM}, {wood, steel}, {matte}},
+			want: []SKU{
+				{VSKUID: "P7-A1-A3-A5", IProdID: 7, Attributes: []SKUAttrib{{"Size", "S"}, {"Material", "Wood"}, {"Finish", "Matte"}}, CDefault: "Y", CStock: "Y"},
+				priced(SKU{VSKUID: "P7-A1-A4-A5", IProdID: 7, Attributes: []SKUAttrib{{"Size", "S"}, {"Material", "Steel"}, {"Finish", "Matte"}}, CDefault: "Y", CStock: "N"}, 50000),
+				priced(SKU{VSKUID: "P7-A2-A3-A5", IProdID: 7, Attributes: []SKUAttrib{{"Size", "M"}, {"Material", "Wood"}, {"Finish", "Matte"}}, CDefault: "N", CStock: "Y"}, 20000),
+				priced(SKU{VSKUID: "P7-A2-A4-A5", IProdID: 7, Attributes: []SKUAttrib{{"Size", "M"}, {"Material", "Steel"}, {"Finish", "Matte"}}, CDefault: "N", CStock: "N"}, 50000),
+			},
+		},
+		{
+			name:       "empty dimension is skipped",
+			dimensions: [][]Variant{{sizeS, sizeM}, {}, {matte}},
+			want: []SKU{
+				{VSKUID: "P7-A1-A5", IProdID: 7, Attributes: []SKUAttrib{{"Size", "S"}, {"Finish", "Matte"}}, CDefault: "Y", CStock: "Y"},
+				priced(SKU{VSKUID: "P7-A2-A5", IProdID: 7, Attributes: []SKUAttrib{{"Size", "M"}, {"Finish", "Matte"}}, CDefault: "N", CStock: "Y"}, 20000),
+			},
+		},
+		{
+			name:       "overrides",
+			dimensions: [][]Variant{{sizeS, sizeM}, {steel}},
+			overrides: map[string]SKUOverride{
+				"P7-A2-A4": {FRetailPrice: moneyPtr(45000), CStock: stringPtr("Y")},
+				"P7-A9":    {CDefault: stringPtr("Y")},
+			},
+			want: []SKU{
+				priced(SKU{VSKUID: "P7-A1-A4", IProdID: 7, Attributes: []SKUAttrib{{"Size", "S"}, {"Material", "Steel"}}, CDefault: "Y", CStock: "N"}, 50000),
+				{VSKUID: "P7-A2-A4", IProdID: 7, Attributes: []SKUAttrib{{"Size", "M"}, {"Material", "Steel"}}, FRetailPrice: 45000, FPrice: 45000, CDefault: "N", CStock: "Y"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := GenerateSKUs(7, test.dimensions, test.overrides); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GenerateSKUs\n got: %+v\nwant: %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestChooseDefault(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		defaults string
+		stock    string
+		want     int
+	}{
+		{"first marked default", "NYY", "YYY", 1},
+		{"single default", "Y", "N", 0},
+		{"first in stock", "NNN", "NNY", 2},
+		{"first", "NNN", "NNN", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			skus := make([]SKU, len(test.defaults))
+			for i := range skus {
+				skus[i].CDefault, skus[i].CStock = test.defaults[i:i+1], test.stock[i:i+1]
+			}
+			chooseDefault(skus)
+
+			for i, sku := range skus {
+				if want := i == test.want; (sku.CDefault == "Y") != want {
+					t.Errorf("SKU %d has CDefault %s, want the default at %d", i, sku.CDefault, test.want)
+				}
+			}
+		})
+	}
+
+	chooseDefault(nil)
+}
+
+func productAttribute(id, attribID uint32, name, value string, price Money, def, stock string) ProductAttribute {
+	return ProductAttribute{
+		IProdAttribID: id,
+		IAttribID:     attribID,
+		VAttribName:   &name,
+		VValue:        &value,
+		FRetailPrice:  price,
+		FPrice:        price * 9 / 10,
+		CDefault:      &def,
+		CStock:        &stock,
+	}
+}
+
+var skuColors = map[uint32]Color{
+	9:  {IColorID: 3, VName: "Red", VColor: "#f00", IRank: 2},
+	11: {IColorID: 4, VName: "Blue", VColor: "#00f", IRank: 1},
+}
+
+func TestVariantDimensions(t *testing.T) {
+
+	attribs := []ProductAttribute{
+		productAttribute(1, 30, "Size", "S", 0, "Y", "Y"),
+		productAttribute(9, ColorAttribID, "Color", "Red", 0, "Y", "A"),
+		productAttribute(3, 20, "Material", "Wood", 0, "Y", "Y"),
+		productAttribute(2, 30, "Size", "M", 20000, "N", "Y"),
+		productAttribute(11, ColorAttribID, "Color", "Blue", 0, "N", "I"),
+	}
+
+	var ids [][]string
+	for _, dimension := range variantDimensions(attribs, skuColors) {
+		var dimIDs []string
+		for _, v := range dimension {
+			dimIDs = append(dimIDs, v.ID)
+		}
+		ids = append(ids, dimIDs)
+	}
+
+	// Colors come first, by rank, then the attributes by iAttribID
+	want := [][]string{{"C11", "C9"}, {"A3"}, {"A1", "A2"}}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("variantDimensions = %v, want %v", ids, want)
+	}
+
+	blue := variantDimensions(attribs, skuColors)[0][0]
+	if blue.Color == nil || blue.Color.VName != "Blue" || blue.Stock {
+		t.Errorf("color variant %+v, want Blue out of stock", blue)
+	}
+}
+
+func TestProductSKUs(t *testing.T) {
+
+	attribs := []ProductAttribute{
+		productAttribute(1, 30, "Size", "S", 0, "N", "N"),
+		productAttribute(2, 30, "Size", "M", 20000, "N", "Y"),
+	}
+	red := "Red"
+	active := "A"
+	colorAttribs := []ProductColorAttribute{{
+		ProductColor:      ProductColor{IPCID: 9, IProdID: 7, IColorID: 3, VColorName: &red, FColorRetailPrice: 10000, CStatus: &active},
+		ProductAttributes: []ProductAttribute{productAttribute(10, 30, "Size", "L", 30000, "N", "Y")},
+	}}
+	overrides := map[string]SKUOverride{"P7-C9-A10": {FOPrice: moneyPtr(25000)}}
+
+	got := productSKUs(7, attribs, colorAttribs, skuColors, overrides)
+
+	redColor := skuColors[9]
+	want := []SKU{
+		{VSKUID: "P7-A1", IProdID: 7, Attributes: []SKUAttrib{{"Size", "S"}}, CDefault: "N", CStock: "N"},
+		priced(SKU{VSKUID: "P7-A2", IProdID: 7, Attributes: []SKUAttrib{{"Size", "M"}}, CDefault: "Y", CStock: "Y"}, 20000),
+		priced(SKU{VSKUID: "P7-C9-A10", IProdID: 7, Attributes: []SKUAttrib{{"Color", "Red"}, {"Size", "L"}}, Color: &redColor, FOPrice: 25000, CDefault: "N", CStock: "Y"}, 30000),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("productSKUs\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+func moneyPtr(m Money) *Money {
+	return &m
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
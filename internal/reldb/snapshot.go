@@ -0,0 +1,110 @@
+package reldb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Snapshot returns a Model whose reads all run on one connection inside a
+// REPEATABLE READ transaction started WITH CONSISTENT SNAPSHOT, so every
+// query sees the database as it was when the snapshot was taken regardless
+// of concurrent edits. Writes still go through the shared pool. Release
+// must be called to end the transaction and return the connection.
+func (m *Model) Snapshot(ctx context.Context) (*Model, error) {
+
+	if m.conn != nil {
+		return nil, fmt.Errorf("model is already a snapshot")
+	}
+
+	conn, err := m.DB.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error acquiring connection for snapshot: %w", err)
+	}
+
+	stmts := []string{
+		"SET TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+		"START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY",
+	}
+	for _, stmt := range stmts {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error starting snapshot (%s): %w", stmt, err)
+		}
+	}
+
+	snapshot := *m
+	snapshot.conn = conn
+
+	return &snapshot, nil
+}
+
+// Release ends the snapshot transaction started by Snapshot. It is a no-op
+// on a Model that is not a snapshot.
+func (m *Model) Release() error {
+
+	if m.conn == nil {
+		return nil
+	}
+
+	_, err := m.conn.ExecContext(context.Background(), "COMMIT")
+	if closeErr := m.conn.Close(); err == nil {
+		err = closeErr
+	}
+	m.conn = nil
+
+	return err
+}
+
+// The read methods below shadow the ones promoted from *sqlx.DB so that a
+// snapshot Model runs every query on its snapshot connection.
+
+func (m *Model) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+
+	if m.conn != nil {
+		return m.conn.SelectContext(ctx, dest, query, args...)
+	}
+
+	return m.DB.SelectContext(ctx, dest, query, args...)
+}
+
+func (m *Model) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+
+	if m.conn != nil {
+		return m.conn.GetContext(ctx, dest, query, args...)
+	}
+
+	return m.DB.GetContext(ctx, dest, query, args...)
+}
+
+func (m *Model) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+
+	if m.conn != nil {
+		return m.conn.QueryxContext(ctx, query, args...)
+	}
+
+	return m.DB.QueryxContext(ctx, query, args...)
+}
+
+func (m *Model) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+
+	if m.conn != nil {
+		return m.conn.QueryRowxContext(ctx, query, args...)
+	}
+
+	return m.DB.QueryRowxContext(ctx, query, args...)
+}
+
+func (m *Model) NamedQueryContext(ctx context.Context, query string, arg any) (*sqlx.Rows, error) {
+
+	if m.conn != nil {
+		qry, args, err := sqlx.Named(query, arg)
+		if err != nil {
+			return nil, err
+		}
+		return m.conn.QueryxContext(ctx, m.Rebind(qry), args...)
+	}
+
+	return m.DB.NamedQueryContext(ctx, query, arg)
+}
@@ -1,6 +1,7 @@
 package reldb
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -48,11 +49,11 @@ func verifyPassword(storedPassword, sentPassword string) bool {
 	return true
 }
 
-func (m *Model) UserIsAuthorized(email string) (bool, error) {
+func (m *Model) UserIsAuthorized(ctx context.Context, email string) (bool, error) {
 
 	query := `SELECT COUNT(*) FROM admin_users WHERE vEmail = ?`
 	var count int
-	if err := m.Get(&count, query, email); err != nil {
+	if err := m.GetContext(ctx, &count, query, email); err != nil {
 		return false, err
 	}
 
@@ -63,7 +64,7 @@ func (m *Model) UserIsAuthorized(email string) (bool, error) {
 	return true, nil
 }
 
-func (m *Model) UpdateUser(user User) (int64, error) {
+func (m *Model) UpdateUser(ctx context.Context, user User) (int64, error) {
 
 	if user.VPasswordHash == nil || *user.VPasswordHash == "" ||
 		user.VEmail == nil || *user.VEmail == "" {
@@ -82,7 +83,7 @@ func (m *Model) UpdateUser(user User) (int64, error) {
 				vPasswordHash = ?
 			WHERE vEmail = ?`
 
-	result, err := m.Exec(query,
+	result, err := m.ExecContext(ctx, query,
 		*user.VFirstName,
 		*user.VLastName,
 		pwHash,
@@ -100,7 +101,7 @@ func (m *Model) UpdateUser(user User) (int64, error) {
 	return count, nil
 }
 
-func (m *Model) Login(email, password string) (User, error) {
+func (m *Model) Login(ctx context.Context, email, password string) (User, error) {
 
 	var user User
 	query := `SELECT
@@ -113,7 +114,7 @@ func (m *Model) Login(email, password string) (User, error) {
 			FROM admin_users 
 			WHERE vEmail = ?`
 
-	if err := m.Get(&user, query, email); err != nil {
+	if err := m.GetContext(ctx, &user, query, email); err != nil {
 		return User{}, err
 	}
 
@@ -124,14 +125,14 @@ func (m *Model) Login(email, password string) (User, error) {
 	return user, nil
 }
 
-func (m *Model) SaveCookie(userID uint, sessionID, cookieStr string, expires time.Time) error {
+func (m *Model) SaveCookie(ctx context.Context, userID uint, sessionID, cookieStr string, expires time.Time) error {
 
 	delQuery := `DELETE FROM session WHERE iUserID = ?`
 	insQuery := `INSERT INTO session 
 				(vSessionID, iUserID, vCookieStr, dtExpires) 
 			VALUES (?, ?, ?, ?)`
 
-	tx, err := m.Beginx()
+	tx, err := m.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -141,11 +142,11 @@ func (m *Model) SaveCookie(userID uint, sessionID, cookieStr string, expires tim
 		}
 	}()
 
-	_, err = tx.Exec(delQuery, userID)
+	_, err = tx.ExecContext(ctx, delQuery, userID)
 	if err != nil {
 		return err
 	}
-	_, err = tx.Exec(insQuery, sessionID, userID, cookieStr, expires)
+	_, err = tx.ExecContext(ctx, insQuery, sessionID, userID, cookieStr, expires)
 	if err != nil {
 		return err
 	}
@@ -156,18 +157,18 @@ func (m *Model) SaveCookie(userID uint, sessionID, cookieStr string, expires tim
 	return nil
 }
 
-func (m *Model) RetrieveCookieString(id string, iUserID uint) (string, error) {
+func (m *Model) RetrieveCookieString(ctx context.Context, id string, iUserID uint) (string, error) {
 
 	var cookieStr string
 	query := `SELECT vCookieStr FROM session WHERE vSessionID = ? AND iUserID = ?`
-	if err := m.Get(&cookieStr, query, id, iUserID); err != nil {
+	if err := m.GetContext(ctx, &cookieStr, query, id, iUserID); err != nil {
 		return "", err
 	}
 
 	return cookieStr, nil
 }
 
-func (m *Model) RetrieveUserByID(iUserID uint) (UserData, error) {
+func (m *Model) RetrieveUserByID(ctx context.Context, iUserID uint) (UserData, error) {
 
 	var userData UserData
 	query := `SELECT
@@ -178,21 +179,21 @@ func (m *Model) RetrieveUserByID(iUserID uint) (UserData, error) {
 			FROM admin_users 
 			WHERE iUserID = ?`
 
-	if err := m.Get(&userData, query, iUserID); err != nil {
+	if err := m.GetContext(ctx, &userData, query, iUserID); err != nil {
 		return userData, err
 	}
 
 	return userData, nil
 }
 
-func (m *Model) VerifyActiveUser(vEmail, vRole string) error {
+func (m *Model) VerifyActiveUser(ctx context.Context, vEmail, vRole string) error {
 
 	query := `SELECT COUNT(*) FROM admin_users a
 				JOIN session s ON a.iUserID = s.iUserID
 				WHERE 
 					a.vEmail = ? AND a.vRole = ?`
 	var count int
-	if err := m.Get(&count, query, vEmail, vRole); err != nil {
+	if err := m.GetContext(ctx, &count, query, vEmail, vRole); err != nil {
 		return err
 	}
 
@@ -0,0 +1,278 @@
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+)
+
+// ChangeKind is how a category differs between the stored tree and the
+// source tree.
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeMoved   ChangeKind = "moved"
+	ChangeRenamed ChangeKind = "renamed"
+)
+
+// Change is one difference of a category. A category that was both moved
+// and renamed has two changes. Removed categories carry their stored name
+// and path.
+type Change struct {
+	Kind        ChangeKind `json:"kind"`
+	IPCatID     uint32     `json:"iPCatID"`
+	VName       string     `json:"vName"`
+	Path        string     `json:"path"`
+	OldName     string     `json:"oldName,omitempty"`
+	IParentID   uint32     `json:"iParentID"`
+	OldParentID *uint32    `json:"oldParentID,omitempty"`
+	OldPath     string     `json:"oldPath,omitempty"`
+}
+
+// Diff is the result of Compare.
+type Diff struct {
+	Changes []Change
+	source  []reldb.CategorySummary
+	stored  []reldb.CategorySummary
+}
+
+// Compare finds the categories of source, as built by CategoryTree, that
+// are missing from, reparented or renamed in stored, the categories read
+// back from the table, and those of stored that are missing from source.
+// Changes follow the order of source, removed categories come last.
+func Compare(source, stored []reldb.CategorySummary) *Diff {
+
+	d := &Diff{source: source, stored: stored}
+	sourceByID := byID(source)
+	storedByID := byID(stored)
+
+	for _, cat := range source {
+		old, exists := storedByID[cat.IPCatID]
+		change := Change{
+			IPCatID:   cat.IPCatID,
+			VName:     cat.VName,
+			Path:      pathOf(sourceByID, cat.IPCatID),
+			IParentID: cat.IParentID,
+		}
+		if !exists {
+			change.Kind = ChangeAdded
+			d.Changes = append(d.Changes, change)
+			continue
+		}
+		if old.IParentID != cat.IParentID {
+			moved := change
+			moved.Kind = ChangeMoved
+			moved.OldParentID = &old.IParentID
+			moved.OldPath = pathOf(storedByID, cat.IPCatID)
+			d.Changes = append(d.Changes, moved)
+		}
+		if old.VName != cat.VName {
+			renamed := change
+			renamed.Kind = ChangeRenamed
+			renamed.OldName = old.VName
+			d.Changes = append(d.Changes, renamed)
+		}
+	}
+
+	for _, old := range stored {
+		if _, exists := sourceByID[old.IPCatID]; !exists {
+			d.Changes = append(d.Changes, Change{
+				Kind:      ChangeRemoved,
+				IPCatID:   old.IPCatID,
+				VName:     old.VName,
+				Path:      pathOf(storedByID, old.IPCatID),
+				IParentID: old.IParentID,
+			})
+		}
+	}
+
+	return d
+}
+
+// Counts is the number of changes of each kind.
+func (d *Diff) Counts() map[ChangeKind]int {
+
+	counts := map[ChangeKind]int{ChangeAdded: 0, ChangeRemoved: 0, ChangeMoved: 0, ChangeRenamed: 0}
+	for _, change := range d.Changes {
+		counts[change.Kind]++
+	}
+
+	return counts
+}
+
+// Render writes the diff to w as JSON or, for any other format, as the
+// source tree with every changed category marked: + added, - removed
+// (shown under its stored parent) and ~ moved or renamed. With
+// changesOnly, branches without changes are left out.
+func (d *Diff) Render(w io.Writer, format Format, changesOnly bool) error {
+
+	if format == FormatJSON {
+		// Paths are joined with " > ", keep it readable
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(struct {
+			Changes []Change           `json:"changes"`
+			Counts  map[ChangeKind]int `json:"counts"`
+		}{d.Changes, d.Counts()}); err != nil {
+			return fmt.Errorf("error marshaling tree diff: %s", err)
+		}
+		return nil
+	}
+
+	var b strings.Builder
+	for _, n := range d.diffTree() {
+		if changesOnly && !n.changed() {
+			continue
+		}
+		n.write(&b, nil, changesOnly)
+	}
+	counts := d.Counts()
+	fmt.Fprintf(&b, "%d added, %d removed, %d moved, %d renamed\n",
+		counts[ChangeAdded], counts[ChangeRemoved], counts[ChangeMoved], counts[ChangeRenamed])
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// diffNode is a category of the text output.
+type diffNode struct {
+	marker   string
+	label    string
+	children []*diffNode
+}
+
+// diffTree builds the source tree, with the removed categories added
+// under their stored parent, and returns its top level: the root, then
+// categories whose parent is in neither tree.
+func (d *Diff) diffTree() []*diffNode {
+
+	changes := map[uint32][]Change{}
+	for _, change := range d.Changes {
+		changes[change.IPCatID] = append(changes[change.IPCatID], change)
+	}
+
+	var cats []reldb.CategorySummary
+	sourceByID := byID(d.source)
+	cats = append(cats, d.source...)
+	for _, cat := range d.stored {
+		if _, exists := sourceByID[cat.IPCatID]; !exists {
+			cats = append(cats, cat)
+		}
+	}
+
+	nodes := map[uint32]*diffNode{}
+	for _, cat := range cats {
+		n := &diffNode{marker: " ", label: fmt.Sprintf("%s [%d]", cat.VName, cat.IPCatID)}
+		for _, change := range changes[cat.IPCatID] {
+			switch change.Kind {
+			case ChangeAdded:
+				n.marker = "+"
+			case ChangeRemoved:
+				n.marker = "-"
+			case ChangeMoved:
+				n.marker = "~"
+				n.label += fmt.Sprintf(" (moved from %s)", change.OldPath)
+			case ChangeRenamed:
+				n.marker = "~"
+				n.label += fmt.Sprintf(" (renamed from %q)", change.OldName)
+			}
+		}
+		nodes[cat.IPCatID] = n
+	}
+
+	// Link in a second pass as stored categories come in SK order, which
+	// may put a child before its parent
+	var tops []*diffNode
+	for _, cat := range cats {
+		n := nodes[cat.IPCatID]
+		if parent, hasParent := nodes[cat.IParentID]; hasParent && cat.IPCatID != 0 && cat.IParentID != cat.IPCatID {
+			parent.children = append(parent.children, n)
+			continue
+		}
+		tops = append(tops, n)
+	}
+
+	return tops
+}
+
+// changed tells if n or any category under it has a change.
+func (n *diffNode) changed() bool {
+
+	if n.marker != " " {
+		return true
+	}
+	for _, child := range n.children {
+		if child.changed() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *diffNode) write(b *strings.Builder, last []bool, changesOnly bool) {
+
+	b.WriteString(n.marker + " ")
+	for i, isLast := range last {
+		switch {
+		case i < len(last)-1 && isLast:
+			b.WriteString("    ")
+		case i < len(last)-1:
+			b.WriteString("│   ")
+		case isLast:
+			b.WriteString("└── ")
+		default:
+			b.WriteString("├── ")
+		}
+	}
+	b.WriteString(n.label + "\n")
+
+	children := n.children
+	if changesOnly {
+		children = nil
+		for _, child := range n.children {
+			if child.changed() {
+				children = append(children, child)
+			}
+		}
+	}
+	for i, child := range children {
+		child.write(b, append(last[:len(last):len(last)], i == len(children)-1), changesOnly)
+	}
+}
+
+func byID(categories []reldb.CategorySummary) map[uint32]reldb.CategorySummary {
+
+	index := make(map[uint32]reldb.CategorySummary, len(categories))
+	for _, cat := range categories {
+		index[cat.IPCatID] = cat
+	}
+
+	return index
+}
+
+// pathOf is the names from the top level category down to iPCatID,
+// computed from the parent IDs so stale stored paths do not matter.
+func pathOf(categories map[uint32]reldb.CategorySummary, iPCatID uint32) string {
+
+	var names []string
+	seen := map[uint32]bool{}
+	for id := iPCatID; !seen[id]; {
+		seen[id] = true
+		cat, exists := categories[id]
+		if !exists || (id == 0 && len(names) > 0) {
+			break
+		}
+		names = append([]string{cat.VName}, names...)
+		id = cat.IParentID
+	}
+
+	return strings.Join(names, " > ")
+}
@@ -0,0 +1,112 @@
+package tree
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func category(iPCatID, iParentID uint32, name string) reldb.CategorySummary {
+	return reldb.CategorySummary{IPCatID: iPCatID, IParentID: iParentID, VName: name}
+}
+
+// source is a tree in the order of CategoryTree: the root, then the rest
+// depth first.
+var source = []reldb.CategorySummary{
+	category(0, 0, "Root"),
+	category(1, 0, "Furniture"),
+	category(2, 1, "Chairs"),
+	category(3, 1, "Tables"),
+	category(6, 1, "Stools"),
+	category(4, 0, "Lamps"),
+	category(5, 4, "Desk Lamps"),
+	category(10, 0, "Textiles"),
+	category(11, 10, "Curtains"),
+}
+
+func ptrTo(id uint32) *uint32 {
+	return &id
+}
+
+func TestCompare(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		stored []reldb.CategorySummary
+		want   []Change
+	}{
+		{
+			name:   "unchanged",
+			stored: source,
+		},
+		{
+			// Stored categories come in SK order, Rugs before its parent
+			// Floor, and Lost has a parent in neither tree
+			name: "changed",
+			stored: []reldb.CategorySummary{
+				category(0, 0, "Root"),
+				category(1, 0, "Furniture"),
+				category(10, 0, "Textiles"),
+				category(11, 10, "Curtains"),
+				category(12, 99, "Lost"),
+				category(2, 1, "Chairs"),
+				category(3, 1, "Tables"),
+				category(4, 0, "Lamps"),
+				category(6, 4, "Bar Stools"),
+				category(7, 9, "Rugs"),
+				category(8, 1, "Outdoor"),
+				category(9, 0, "Floor"),
+			},
+			want: []Change{
+				{Kind: ChangeMoved, IPCatID: 6, VName: "Stools", Path: "Furniture > Stools", IParentID: 1, OldParentID: ptrTo(4), OldPath: "Lamps > Bar Stools"},
+				{Kind: ChangeRenamed, IPCatID: 6, VName: "Stools", Path: "Furniture > Stools", OldName: "Bar Stools", IParentID: 1},
+				{Kind: ChangeAdded, IPCatID: 5, VName: "Desk Lamps", Path: "Lamps > Desk Lamps", IParentID: 4},
+				{Kind: ChangeRemoved, IPCatID: 12, VName: "Lost", Path: "Lost", IParentID: 99},
+				{Kind: ChangeRemoved, IPCatID: 7, VName: "Rugs", Path: "Floor > Rugs", IParentID: 9},
+				{Kind: ChangeRemoved, IPCatID: 8, VName: "Outdoor", Path: "Furniture > Outdoor", IParentID: 1},
+				{Kind: ChangeRemoved, IPCatID: 9, VName: "Floor", Path: "Floor", IParentID: 0},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			d := Compare(source, test.stored)
+			if !reflect.DeepEqual(d.Changes, test.want) {
+				t.Errorf("Compare\n got: %+v\nwant: %+v", d.Changes, test.want)
+			}
+
+			for _, changesOnly := range []bool{false, true} {
+				var b bytes.Buffer
+				if err := d.Render(&b, FormatText, changesOnly); err != nil {
+					t.Fatal(err)
+				}
+
+				golden := filepath.Join("testdata", "diff_"+test.name+".golden")
+				if changesOnly {
+					golden = filepath.Join("testdata", "diff_"+test.name+"_changes_only.golden")
+				}
+				if *update {
+					if err := os.WriteFile(golden, b.Bytes(), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(b.Bytes(), want) {
+					t.Errorf("diff differs from %s\n got:\n%s\nwant:\n%s", golden, b.Bytes(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Package tree renders the category tree as text, JSON, Graphviz DOT or
+// Mermaid.
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gurunandan-bhat/sql-to-nosql/internal/reldb"
+)
+
+// Format is an output format of Render.
+type Format string
+
+const (
+	FormatText    Format = "text"
+	FormatJSON    Format = "json"
+	FormatDOT     Format = "dot"
+	FormatMermaid Format = "mermaid"
+)
+
+// ParseFormat checks a format given on the command line.
+func ParseFormat(s string) (Format, error) {
+
+	switch format := Format(s); format {
+	case FormatText, FormatJSON, FormatDOT, FormatMermaid:
+		return format, nil
+	}
+
+	return "", fmt.Errorf("unknown format %q (want text, json, dot or mermaid)", s)
+}
+
+// Options choose what Render shows. JSON always has every field. Children
+// are rendered in the order of CategoryTree.
+type Options struct {
+	ShowIDs    bool
+	ShowStatus bool
+	ShowCounts bool
+}
+
+// Find returns the category iPCatID of the tree under root.
+func Find(root *reldb.CategorySummary, iPCatID uint32) (*reldb.CategorySummary, error) {
+
+	var found *reldb.CategorySummary
+	walk(root, func(cat *reldb.CategorySummary, parent *reldb.CategorySummary, depth int, last []bool) {
+		if found == nil && cat.IPCatID == iPCatID {
+			found = cat
+		}
+	})
+	if found == nil {
+		return nil, fmt.Errorf("category %d is not in the tree", iPCatID)
+	}
+
+	return found, nil
+}
+
+// Render writes the tree under root, root included, to w.
+func Render(w io.Writer, root *reldb.CategorySummary, format Format, opts Options) error {
+
+	switch format {
+	case FormatJSON:
+		return renderJSON(w, root, opts)
+	case FormatDOT:
+		return renderGraph(w, root, opts, "digraph categories {\n\tnode [shape=box];\n", "\tn%d [label=\"%s\"];\n", "\tn%d -> n%d;\n", "}\n", dotEscape)
+	case FormatMermaid:
+		return renderGraph(w, root, opts, "graph TD\n", "\tn%d[\"%s\"]\n", "\tn%d --> n%d\n", "", mermaidEscape)
+	}
+
+	return renderText(w, root, opts)
+}
+
+// walk calls visit for every category under root in depth first order.
+// last tells for the category and each of its ancestors below root if it
+// is the last child of its parent. A category met twice, which only
+// happens with a cycle in the data, is not descended into again.
+func walk(root *reldb.CategorySummary, visit func(cat, parent *reldb.CategorySummary, depth int, last []bool)) {
+
+	seen := map[uint32]bool{}
+	var descend func(cat, parent *reldb.CategorySummary, depth int, last []bool)
+	descend = func(cat, parent *reldb.CategorySummary, depth int, last []bool) {
+
+		visit(cat, parent, depth, last)
+		if seen[cat.IPCatID] {
+			return
+		}
+		seen[cat.IPCatID] = true
+
+		for i, child := range cat.Children {
+			descend(child, cat, depth+1, append(last[:len(last):len(last)], i == len(cat.Children)-1))
+		}
+	}
+	descend(root, nil, 0, nil)
+}
+
+// label is the name of a category with the details opts ask for.
+func label(cat *reldb.CategorySummary, opts Options) string {
+
+	l := cat.VName
+	if opts.ShowIDs {
+		l += fmt.Sprintf(" [%d]", cat.IPCatID)
+	}
+	if opts.ShowStatus && cat.CStatus != "" {
+		l += fmt.Sprintf(" (%s)", cat.CStatus)
+	}
+	if opts.ShowCounts {
+		l += fmt.Sprintf(" %d products", cat.IProductCount)
+	}
+
+	return l
+}
+
+func renderText(w io.Writer, root *reldb.CategorySummary, opts Options) error {
+
+	var err error
+	walk(root, func(cat, parent *reldb.CategorySummary, depth int, last []bool) {
+		if err != nil {
+			return
+		}
+
+		var prefix strings.Builder
+		for i, isLast := range last {
+			switch {
+			case i < len(last)-1 && isLast:
+				prefix.WriteString("    ")
+			case i < len(last)-1:
+				prefix.WriteString("│   ")
+			case isLast:
+				prefix.WriteString("└── ")
+			default:
+				prefix.WriteString("├── ")
+			}
+		}
+		_, err = fmt.Fprintf(w, "%s%s\n", prefix.String(), label(cat, opts))
+	})
+
+	return err
+}
+
+// node is a category in the JSON output.
+type node struct {
+	IPCatID       uint32  `json:"iPCatID"`
+	VName         string  `json:"vName"`
+	VURLName      string  `json:"vUrlName"`
+	CStatus       string  `json:"cStatus,omitempty"`
+	IProductCount int     `json:"iProductCount"`
+	Children      []*node `json:"children,omitempty"`
+}
+
+func renderJSON(w io.Writer, root *reldb.CategorySummary, opts Options) error {
+
+	nodes := map[*reldb.CategorySummary]*node{}
+	walk(root, func(cat, parent *reldb.CategorySummary, depth int, last []bool) {
+		n := &node{
+			IPCatID:       cat.IPCatID,
+			VName:         cat.VName,
+			VURLName:      cat.VURLName,
+			CStatus:       cat.CStatus,
+			IProductCount: cat.IProductCount,
+		}
+		if _, seen := nodes[cat]; !seen {
+			nodes[cat] = n
+		}
+		if parent != nil {
+			nodes[parent].Children = append(nodes[parent].Children, n)
+		}
+	})
+
+	jsonBytes, err := json.MarshalIndent(nodes[root], "", "\t")
+	if err != nil {
+		return fmt.Errorf("error marshaling tree: %s", err)
+	}
+	_, err = fmt.Fprintln(w, string(jsonBytes))
+
+	return err
+}
+
+func renderGraph(w io.Writer, root *reldb.CategorySummary, opts Options, header, nodeFmt, edgeFmt, footer string, escape func(string) string) error {
+
+	var b strings.Builder
+	b.WriteString(header)
+	declared := map[uint32]bool{}
+	walk(root, func(cat, parent *reldb.CategorySummary, depth int, last []bool) {
+		if !declared[cat.IPCatID] {
+			declared[cat.IPCatID] = true
+			fmt.Fprintf(&b, nodeFmt, cat.IPCatID, escape(label(cat, opts)))
+		}
+		if parent != nil {
+			fmt.Fprintf(&b, edgeFmt, parent.IPCatID, cat.IPCatID)
+		}
+	})
+	b.WriteString(footer)
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
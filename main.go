@@ -6,8 +6,15 @@ package main
 import (
 	"github.com/gurunandan-bhat/sql-to-nosql/cmd"
 	_ "github.com/gurunandan-bhat/sql-to-nosql/cmd/category"
+	_ "github.com/gurunandan-bhat/sql-to-nosql/cmd/counts"
+	_ "github.com/gurunandan-bhat/sql-to-nosql/cmd/images"
+	_ "github.com/gurunandan-bhat/sql-to-nosql/cmd/lint"
+	_ "github.com/gurunandan-bhat/sql-to-nosql/cmd/migrate"
+	_ "github.com/gurunandan-bhat/sql-to-nosql/cmd/migratequery"
 	_ "github.com/gurunandan-bhat/sql-to-nosql/cmd/product"
 	_ "github.com/gurunandan-bhat/sql-to-nosql/cmd/recipients"
+	_ "github.com/gurunandan-bhat/sql-to-nosql/cmd/replay"
+	_ "github.com/gurunandan-bhat/sql-to-nosql/cmd/schema"
 	_ "github.com/gurunandan-bhat/sql-to-nosql/cmd/tree"
 )
 
@@ -1,6 +1,6 @@
 select 
-	concat('#SET:', s.vRecipientSetName),
-	concat('#A:', r.vEmail),
+	concat('#SET:', s.vRecipientSetName) PK,
+	concat('#A:', r.vEmail) SK,
 	s.vRecipientSetName,
 	r.vEmail,
 	r.vFullname,